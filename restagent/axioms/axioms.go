@@ -0,0 +1,300 @@
+package axioms
+
+import (
+	"fmt"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+/*
+* Axiomatic properties of the voting rules
+* A property is checked by searching for a counterexample: a profile (and, for the properties
+* comparing two profiles, a modified profile) on which the rule does not behave as required.
+* The search goes by increasing number of voters, then of alternatives, so that the first
+* counterexample found is the smallest one (see search.go). Finding no counterexample does not
+* prove the property, it only means that none exists among the profiles tried
+ */
+
+// Rule under test: gives the winners of a profile on the alternatives 1..m
+// (several if they are tied, none if there is no winner). See rules.go to build it from comsoc
+type SCF func(p comsoc.Profile) ([]comsoc.Alternative, error)
+
+const (
+	Condorcet     = "condorcet"     // The Condorcet winner, if any, is the only winner
+	Majority      = "majority"      // An alternative ranked first by more than half of the voters is the only winner
+	Monotonicity  = "monotonicity"  // A winner remains a winner when a voter ranks it one place higher
+	Participation = "participation" // A new voter never gets winners they all like less than the winners without their vote (no-show paradox)
+	Pareto        = "pareto"        // No winner is ranked below the same alternative by all the voters
+	Clones        = "clones"        // Cloning an alternative changes neither whether a clone wins nor whether the other alternatives win
+	Reversal      = "reversal"      // The only winner is not the only winner when all the rankings are reversed
+)
+
+var Properties = []string{Condorcet, Majority, Monotonicity, Participation, Pareto, Clones, Reversal}
+
+// Profile on which a rule violates a property
+type Counterexample struct {
+	Property        string
+	Profile         comsoc.Profile
+	Winners         []comsoc.Alternative
+	Modified        comsoc.Profile       // Modified profile, for the properties comparing two profiles (nil otherwise)
+	ModifiedWinners []comsoc.Alternative // Winners of the modified profile
+	Reason          string               // Why the property is violated
+}
+
+func (c Counterexample) String() string {
+	if c.Modified == nil {
+		return fmt.Sprintf("%s: profile %v, winners %v", c.Reason, c.Profile, c.Winners)
+	}
+	return fmt.Sprintf("%s: profile %v, winners %v; modified profile %v, winners %v", c.Reason, c.Profile, c.Winners, c.Modified, c.ModifiedWinners)
+}
+
+// Searches a counterexample derived from the profile p, whose winners are given,
+// rankings being all the rankings of its alternatives
+type checker func(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error)
+
+var checkers = map[string]checker{
+	Condorcet:     checkCondorcet,
+	Majority:      checkMajority,
+	Monotonicity:  checkMonotonicity,
+	Participation: checkParticipation,
+	Pareto:        checkPareto,
+	Clones:        checkClones,
+	Reversal:      checkReversal,
+}
+
+// Above this number of voters, the clone is placed either above or below the cloned alternative
+// by all the voters, instead of trying every placement
+const maxCloneVoters = 4
+
+func checkCondorcet(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	cw, err := comsoc.CondorcetWinner(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(cw) == 1 && !isOnly(cw[0], winners) {
+		return &Counterexample{Property: Condorcet, Profile: p, Winners: winners,
+			Reason: fmt.Sprintf("%d is the Condorcet winner but not the only winner", cw[0])}, nil
+	}
+	return nil, nil
+}
+
+func checkMajority(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	m := len(rankings[0])
+	firsts := make(map[comsoc.Alternative]int, m)
+	for _, ranking := range p {
+		firsts[ranking[0]]++
+	}
+	for alt, nb := range firsts {
+		if 2*nb > len(p) && !isOnly(alt, winners) {
+			return &Counterexample{Property: Majority, Profile: p, Winners: winners,
+				Reason: fmt.Sprintf("%d is ranked first by %d of the %d voters but is not the only winner", alt, nb, len(p))}, nil
+		}
+	}
+	return nil, nil
+}
+
+func checkMonotonicity(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	for _, w := range winners {
+		for i, ranking := range p {
+			pos := position(w, ranking)
+			if pos == 0 || (i > 0 && equalRankings(ranking, p[i-1])) {
+				continue // already first, or same modified profile as for the previous voter
+			}
+			raised := append([]comsoc.Alternative{}, ranking...)
+			raised[pos-1], raised[pos] = raised[pos], raised[pos-1]
+			modified := replaceRanking(p, i, raised)
+			modifiedWinners, err := rule(modified)
+			if err != nil {
+				return nil, ruleError(modified, err)
+			}
+			if !contains(w, modifiedWinners) {
+				return &Counterexample{Property: Monotonicity, Profile: p, Winners: winners, Modified: modified, ModifiedWinners: modifiedWinners,
+					Reason: fmt.Sprintf("voter %d ranks the winner %d one place higher and it no longer wins", i+1, w)}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func checkParticipation(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	if len(winners) == 0 {
+		return nil, nil
+	}
+	for _, ranking := range rankings {
+		modified := append(append(comsoc.Profile{}, p...), ranking)
+		modifiedWinners, err := rule(modified)
+		if err != nil {
+			return nil, ruleError(modified, err)
+		}
+		if len(modifiedWinners) == 0 {
+			continue
+		}
+		// the new voter prefers each winner without their vote to each winner with it
+		if worstPosition(winners, ranking) < bestPosition(modifiedWinners, ranking) {
+			return &Counterexample{Property: Participation, Profile: p, Winners: winners, Modified: modified, ModifiedWinners: modifiedWinners,
+				Reason: fmt.Sprintf("a new voter with ranking %v prefers the winners without their vote", ranking)}, nil
+		}
+	}
+	return nil, nil
+}
+
+func checkPareto(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	m := len(rankings[0])
+	for _, w := range winners {
+		for alt := comsoc.Alternative(1); int(alt) <= m; alt++ {
+			if alt == w {
+				continue
+			}
+			var dominated = true
+			for _, ranking := range p {
+				if position(alt, ranking) > position(w, ranking) {
+					dominated = false
+					break
+				}
+			}
+			if dominated {
+				return &Counterexample{Property: Pareto, Profile: p, Winners: winners,
+					Reason: fmt.Sprintf("all the voters prefer %d to the winner %d", alt, w)}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func checkClones(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	m := len(rankings[0])
+	clone := comsoc.Alternative(m + 1)
+	var masks []int // bit i set if voter i ranks the clone just above the cloned alternative
+	if len(p) <= maxCloneVoters {
+		for mask := 0; mask < 1<<len(p); mask++ {
+			masks = append(masks, mask)
+		}
+	} else {
+		masks = []int{0, 1<<len(p) - 1}
+	}
+	for cloned := comsoc.Alternative(1); int(cloned) <= m; cloned++ {
+		for _, mask := range masks {
+			modified := make(comsoc.Profile, len(p))
+			for i, ranking := range p {
+				modified[i] = insertClone(ranking, cloned, clone, mask&(1<<i) != 0)
+			}
+			modifiedWinners, err := rule(modified)
+			if err != nil {
+				return nil, ruleError(modified, err)
+			}
+			if contains(cloned, winners) != (contains(cloned, modifiedWinners) || contains(clone, modifiedWinners)) {
+				return &Counterexample{Property: Clones, Profile: p, Winners: winners, Modified: modified, ModifiedWinners: modifiedWinners,
+					Reason: fmt.Sprintf("cloning %d (clone %d) changes whether one of the clones wins", cloned, clone)}, nil
+			}
+			for alt := comsoc.Alternative(1); int(alt) <= m; alt++ {
+				if alt != cloned && contains(alt, winners) != contains(alt, modifiedWinners) {
+					return &Counterexample{Property: Clones, Profile: p, Winners: winners, Modified: modified, ModifiedWinners: modifiedWinners,
+						Reason: fmt.Sprintf("cloning %d (clone %d) changes whether %d wins", cloned, clone, alt)}, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+func checkReversal(rule SCF, p comsoc.Profile, rankings [][]comsoc.Alternative, winners []comsoc.Alternative) (*Counterexample, error) {
+	if len(winners) != 1 {
+		return nil, nil
+	}
+	modified := make(comsoc.Profile, len(p))
+	for i, ranking := range p {
+		modified[i] = make([]comsoc.Alternative, len(ranking))
+		for j, alt := range ranking {
+			modified[i][len(ranking)-1-j] = alt
+		}
+	}
+	modifiedWinners, err := rule(modified)
+	if err != nil {
+		return nil, ruleError(modified, err)
+	}
+	if isOnly(winners[0], modifiedWinners) {
+		return &Counterexample{Property: Reversal, Profile: p, Winners: winners, Modified: modified, ModifiedWinners: modifiedWinners,
+			Reason: fmt.Sprintf("%d is the only winner of both the profile and its reverse", winners[0])}, nil
+	}
+	return nil, nil
+}
+
+///// Utility functions
+
+func ruleError(p comsoc.Profile, err error) error {
+	return fmt.Errorf("rule failed on profile %v: %s", p, err.Error())
+}
+
+// Returns the index where alt is found in ranking
+func position(alt comsoc.Alternative, ranking []comsoc.Alternative) int {
+	for i, a := range ranking {
+		if a == alt {
+			return i
+		}
+	}
+	return -1
+}
+
+// Best (smallest) position of the alternatives of alts in ranking
+func bestPosition(alts []comsoc.Alternative, ranking []comsoc.Alternative) int {
+	var best = len(ranking)
+	for _, alt := range alts {
+		if pos := position(alt, ranking); pos < best {
+			best = pos
+		}
+	}
+	return best
+}
+
+// Worst (largest) position of the alternatives of alts in ranking
+func worstPosition(alts []comsoc.Alternative, ranking []comsoc.Alternative) int {
+	var worst = -1
+	for _, alt := range alts {
+		if pos := position(alt, ranking); pos > worst {
+			worst = pos
+		}
+	}
+	return worst
+}
+
+func contains(alt comsoc.Alternative, alts []comsoc.Alternative) bool {
+	return position(alt, alts) >= 0
+}
+
+func isOnly(alt comsoc.Alternative, alts []comsoc.Alternative) bool {
+	return len(alts) == 1 && alts[0] == alt
+}
+
+func equalRankings(r1, r2 []comsoc.Alternative) bool {
+	if len(r1) != len(r2) {
+		return false
+	}
+	for i := range r1 {
+		if r1[i] != r2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy of p where the i-th ranking is replaced
+func replaceRanking(p comsoc.Profile, i int, ranking []comsoc.Alternative) comsoc.Profile {
+	res := append(comsoc.Profile{}, p...)
+	res[i] = ranking
+	return res
+}
+
+// Copy of ranking with clone just above (or below) cloned
+func insertClone(ranking []comsoc.Alternative, cloned, clone comsoc.Alternative, above bool) []comsoc.Alternative {
+	res := make([]comsoc.Alternative, 0, len(ranking)+1)
+	for _, alt := range ranking {
+		if alt == cloned && above {
+			res = append(res, clone)
+		}
+		res = append(res, alt)
+		if alt == cloned && !above {
+			res = append(res, clone)
+		}
+	}
+	return res
+}
@@ -0,0 +1,94 @@
+package axioms
+
+import (
+	"errors"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+/*
+* Rules under test, built from the functions of comsoc
+* The SCFs of comsoc (e.g. comsoc.BordaSCF) are already of type SCF. The SWFs give the alternatives
+* with the best score, and the functions taking a tie-break use the order 1..m, so that they give
+* a single winner (the properties are then checked on the rule with this tie-break)
+ */
+
+// Winners of a SWF: the alternatives with the best count
+func FromSWF(swf func(p comsoc.Profile) (comsoc.Count, error)) SCF {
+	return func(p comsoc.Profile) ([]comsoc.Alternative, error) {
+		count, err := swf(p)
+		if err != nil {
+			return nil, err
+		}
+		return bestAlternatives(comsoc.ToFloatCount(count)), nil
+	}
+}
+
+// Winners of a SWF giving real scores (e.g. comsoc.PositionalSWF(scoreVector))
+func FromFloatSWF(swf func(p comsoc.Profile) (comsoc.FloatCount, error)) SCF {
+	return func(p comsoc.Profile) ([]comsoc.Alternative, error) {
+		count, err := swf(p)
+		if err != nil {
+			return nil, err
+		}
+		return bestAlternatives(count), nil
+	}
+}
+
+// Winner of a SWF breaking the ties itself (e.g. comsoc.STV_SWF_TieBreak), with the tie-break 1..m
+func FromTieBreakSWF(swf func(p comsoc.Profile, tieBreak []comsoc.Alternative) ([]comsoc.Alternative, error)) SCF {
+	return func(p comsoc.Profile) ([]comsoc.Alternative, error) {
+		ranking, err := swf(p, increasingOrder(p))
+		if err != nil {
+			return nil, err
+		}
+		if len(ranking) == 0 {
+			return nil, nil
+		}
+		return []comsoc.Alternative{ranking[0]}, nil
+	}
+}
+
+// Winners of a registered rule with its options. The winners of a Scorer are the alternatives with
+// the best score, the other rules are computed with the tie-break 1..m
+func FromRule(rule comsoc.Rule, options comsoc.RuleOptions) (SCF, error) {
+	if rule.Format() != comsoc.RankingFormat || options.AllowPartial {
+		return nil, errors.New("properties are only checked on complete rankings")
+	}
+	return func(p comsoc.Profile) ([]comsoc.Alternative, error) {
+		votes := comsoc.Votes{Alts: increasingOrder(p), Profile: p}
+		if scorer, ok := rule.(comsoc.Scorer); ok {
+			count, err := scorer.Scores(votes, options)
+			if err != nil {
+				return nil, err
+			}
+			return bestAlternatives(count), nil
+		}
+		res, err := rule.Compute(votes, options, votes.Alts)
+		if err != nil {
+			return nil, err
+		}
+		if res.Winner == 0 {
+			return nil, nil
+		}
+		return []comsoc.Alternative{res.Winner}, nil
+	}, nil
+}
+
+// Alternatives with the best score, in increasing order
+func bestAlternatives(count comsoc.FloatCount) []comsoc.Alternative {
+	groups := comsoc.TiedGroups(count)
+	if len(groups) == 0 {
+		return nil
+	}
+	return groups[0]
+}
+
+// Alternatives 1..m of the profile
+func increasingOrder(p comsoc.Profile) []comsoc.Alternative {
+	res := make([]comsoc.Alternative, len(p[0]))
+	for i := range res {
+		res[i] = comsoc.Alternative(i + 1)
+	}
+	return res
+}
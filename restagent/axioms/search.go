@@ -0,0 +1,172 @@
+package axioms
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+// Limits of the search of counterexamples
+type Config struct {
+	MaxVoters       int   // Largest number of voters tried (from 1)
+	MaxAlts         int   // Largest number of alternatives tried (from 2)
+	ExhaustiveLimit int   // Sizes with at most this number of profiles are enumerated exhaustively
+	Samples         int   // Number of random profiles tried for the larger sizes
+	Seed            int64 // Seed of the random profiles, so that a search can be replayed
+}
+
+var DefaultConfig = Config{MaxVoters: 7, MaxAlts: 4, ExhaustiveLimit: 20000, Samples: 1000, Seed: 1}
+
+// Smallest counterexample found for each property (nil if none was found)
+type Report map[string]*Counterexample
+
+// Searches the smallest counterexample to a property
+func Check(rule SCF, property string, config Config) (*Counterexample, error) {
+	report, err := CheckAll(rule, []string{property}, config)
+	if err != nil {
+		return nil, err
+	}
+	return report[property], nil
+}
+
+// Searches the smallest counterexample to each of the properties (all of them if properties is nil).
+// The profiles are tried by increasing number of voters, then of alternatives. As the rules of comsoc
+// are anonymous, a size is enumerated exhaustively up to the order of the voters if it has at most
+// config.ExhaustiveLimit profiles, and config.Samples random profiles are drawn otherwise
+func CheckAll(rule SCF, properties []string, config Config) (Report, error) {
+	if properties == nil {
+		properties = Properties
+	}
+	for _, property := range properties {
+		if _, ok := checkers[property]; !ok {
+			return nil, fmt.Errorf("unknown property %s", property)
+		}
+	}
+	if config.MaxVoters < 1 || config.MaxAlts < 2 {
+		return nil, fmt.Errorf("at least 1 voter and 2 alternatives are needed")
+	}
+
+	report := make(Report, len(properties))
+	for _, property := range properties {
+		report[property] = nil
+	}
+	remaining := append([]string{}, properties...)
+	rng := rand.New(rand.NewSource(config.Seed))
+
+	// checks the remaining properties on p, and removes the violated ones
+	check := func(p comsoc.Profile, rankings [][]comsoc.Alternative) error {
+		winners, err := rule(p)
+		if err != nil {
+			return ruleError(p, err)
+		}
+		var left []string
+		for _, property := range remaining {
+			counterexample, err := checkers[property](rule, p, rankings, winners)
+			if err != nil {
+				return err
+			}
+			if counterexample != nil {
+				report[property] = counterexample
+			} else {
+				left = append(left, property)
+			}
+		}
+		remaining = left
+		return nil
+	}
+
+	for n := 1; n <= config.MaxVoters && len(remaining) > 0; n++ {
+		for m := 2; m <= config.MaxAlts && len(remaining) > 0; m++ {
+			rankings := permutations(m)
+			if nbProfiles(len(rankings), n, config.ExhaustiveLimit) <= config.ExhaustiveLimit {
+				// multisets of n rankings, given by non-decreasing indices in rankings
+				idx := make([]int, n)
+				for ok := true; ok && len(remaining) > 0; ok = nextMultiset(idx, len(rankings)) {
+					if err := check(profileOf(rankings, idx), rankings); err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				idx := make([]int, n)
+				for s := 0; s < config.Samples && len(remaining) > 0; s++ {
+					for i := range idx {
+						idx[i] = rng.Intn(len(rankings))
+					}
+					if err := check(profileOf(rankings, idx), rankings); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// Profile whose i-th ranking is rankings[idx[i]]
+func profileOf(rankings [][]comsoc.Alternative, idx []int) comsoc.Profile {
+	p := make(comsoc.Profile, len(idx))
+	for i, r := range idx {
+		p[i] = rankings[r]
+	}
+	return p
+}
+
+// Next non-decreasing sequence of indices in [0, k), in lexicographic order. Returns false after the last one
+func nextMultiset(idx []int, k int) bool {
+	for i := len(idx) - 1; i >= 0; i-- {
+		if idx[i] < k-1 {
+			idx[i]++
+			for j := i + 1; j < len(idx); j++ {
+				idx[j] = idx[i]
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Number of multisets of n elements among k, i.e. C(k+n-1, n), or a number above limit if it is larger
+func nbProfiles(k int, n int, limit int) int {
+	var res = 1
+	for i := 1; i <= n; i++ {
+		res = res * (k + i - 1) / i
+		if res > limit {
+			return limit + 1
+		}
+	}
+	return res
+}
+
+// Rankings of the alternatives 1..m, in lexicographic order
+func permutations(m int) [][]comsoc.Alternative {
+	if m == 0 {
+		return [][]comsoc.Alternative{{}}
+	}
+	var res [][]comsoc.Alternative
+	for _, sub := range permutations(m - 1) {
+		// inserts m at each position of the rankings of 1..m-1
+		for pos := 0; pos <= len(sub); pos++ {
+			ranking := make([]comsoc.Alternative, 0, m)
+			ranking = append(ranking, sub[:pos]...)
+			ranking = append(ranking, comsoc.Alternative(m))
+			ranking = append(ranking, sub[pos:]...)
+			res = append(res, ranking)
+		}
+	}
+	sortRankings(res)
+	return res
+}
+
+// Sorts rankings in lexicographic order
+func sortRankings(rankings [][]comsoc.Alternative) {
+	sort.Slice(rankings, func(i, j int) bool {
+		for k := range rankings[i] {
+			if rankings[i][k] != rankings[j][k] {
+				return rankings[i][k] < rankings[j][k]
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/axioms"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+// Searches counterexamples to the axiomatic properties for the registered rules given as
+// arguments (all the rules by default). Rules needing options or other votes than rankings are skipped
+func main() {
+	rules := os.Args[1:]
+	if len(rules) == 0 {
+		rules = restagent.Rules
+	}
+	for _, name := range rules {
+		fmt.Printf("\n===== %s =====\n", name)
+		rule, found := restagent.LookupRule(name)
+		if !found {
+			fmt.Println("unknown rule")
+			continue
+		}
+		if err := rule.CheckOptions(comsoc.RuleOptions{}, axioms.DefaultConfig.MaxAlts); err != nil {
+			fmt.Println("skipped:", err)
+			continue
+		}
+		scf, err := axioms.FromRule(rule, comsoc.RuleOptions{})
+		if err != nil {
+			fmt.Println("skipped:", err)
+			continue
+		}
+		report, err := axioms.CheckAll(scf, nil, axioms.DefaultConfig)
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		for _, property := range axioms.Properties {
+			if report[property] == nil {
+				fmt.Printf("%-14s no counterexample found\n", property)
+			} else {
+				fmt.Printf("%-14s %v\n", property, report[property])
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/instances"
+)
+
+// Searches the cheapest attacks (bribery, control and cloning) making a target win or lose the simple majority
+// on the example slide 14, chapter 2 (10 x [1, 2, 3], 6 x [2, 3, 1], 5 x [3, 2, 1]), then replays one of them
+// against the server. The pool of voters which can be added is 4 x [2, 1, 3], and the spoiler 4, ranked just before 1
+// by the voters ranking 1 first and last by the others, can be added as a candidate
+func main() {
+	var target int
+	var constructive string
+	var maxCost int
+	var kind string
+
+	fmt.Println("Target alternative (1, 2 or 3) ?")
+	fmt.Scanln(&target)
+	fmt.Println("Constructive (y/n) ?")
+	fmt.Scanln(&constructive)
+	fmt.Println("Maximal cost ?")
+	fmt.Scanln(&maxCost)
+	if target < 1 || target > 3 || maxCost < 1 {
+		fmt.Println("the target should be 1, 2 or 3, and the maximal cost at least 1")
+		return
+	}
+
+	var p, withSpoiler comsoc.Profile
+	add := func(nb int, ranking []comsoc.Alternative, spoiled []comsoc.Alternative) {
+		for i := 0; i < nb; i++ {
+			p = append(p, ranking)
+			withSpoiler = append(withSpoiler, spoiled)
+		}
+	}
+	add(10, []comsoc.Alternative{1, 2, 3}, []comsoc.Alternative{4, 1, 2, 3})
+	add(6, []comsoc.Alternative{2, 3, 1}, []comsoc.Alternative{2, 3, 1, 4})
+	add(5, []comsoc.Alternative{3, 2, 1}, []comsoc.Alternative{3, 2, 1, 4})
+	pool := comsoc.Profile{{2, 1, 3}, {2, 1, 3}, {2, 1, 3}, {2, 1, 3}}
+
+	rule, _ := restagent.LookupRule(restagent.Majority)
+	tieBreak := []comsoc.Alternative{1, 2, 3, 4}
+	winner := comsoc.ProfileWinner(rule, comsoc.RuleOptions{}, tieBreak)
+	alt := comsoc.Alternative(target)
+	c := constructive != "n"
+
+	var attacks []*comsoc.Attack
+	for _, search := range []func() (*comsoc.Attack, error){
+		func() (*comsoc.Attack, error) { return comsoc.Bribery(p, winner, alt, c, maxCost) },
+		func() (*comsoc.Attack, error) { return comsoc.AddVoters(p, pool, winner, alt, c, maxCost) },
+		func() (*comsoc.Attack, error) { return comsoc.DeleteVoters(p, winner, alt, c, maxCost) },
+		func() (*comsoc.Attack, error) {
+			return comsoc.AddCandidates(withSpoiler, []comsoc.Alternative{4}, winner, alt, c, maxCost)
+		},
+		func() (*comsoc.Attack, error) { return comsoc.DeleteCandidates(p, winner, alt, c, maxCost) },
+		func() (*comsoc.Attack, error) { return comsoc.Cloning(p, winner, alt, c, maxCost) },
+	} {
+		attack, err := search()
+		if err != nil {
+			fmt.Println("error:", err.Error())
+			return
+		}
+		if attack == nil {
+			continue
+		}
+		attacks = append(attacks, attack)
+		exact := ""
+		if !attack.Exact {
+			exact = " (at most)"
+		}
+		fmt.Printf("%-18s cost %d%s, voters %v, candidates %v, winner %d\n", attack.Kind, attack.Cost, exact, attack.Voters, attack.Candidates, attack.Winner)
+	}
+	if len(attacks) == 0 {
+		fmt.Printf("no attack of cost at most %d was found\n", maxCost)
+		return
+	}
+
+	fmt.Println("Attack to replay ?")
+	fmt.Scanln(&kind)
+	for _, attack := range attacks {
+		if attack.Kind == kind {
+			// the server expects the alternatives 1..m
+			replayed, labels := comsoc.RelabelProfile(attack.Profile)
+			fmt.Printf("alternatives %v are relabelled 1..%d\n", labels, len(labels))
+			replayedTieBreak := make([]comsoc.Alternative, len(labels)) // the order of tieBreak is kept by the relabelling
+			for i := range replayedTieBreak {
+				replayedTieBreak[i] = comsoc.Alternative(i + 1)
+			}
+			instances.LaunchAgents(1, len(replayed), len(labels), instances.InitProfileAgents(restagent.Majority, restagent.BallotOptions{}, replayedTieBreak, replayed))
+			return
+		}
+	}
+	fmt.Println("no attack of this kind was found")
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/endpoints"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/restserveragent"
+)
+
+// Launches the server with an additional rule, the veto rule (each voter gives a point
+// to all the alternatives but their last one), registered without modifying the server
+func main() {
+	veto := comsoc.FuncRule{
+		RuleName:     "veto",
+		BallotFormat: comsoc.RankingFormat,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			nbAlts := len(votes.Alts)
+			swf := comsoc.FloatSWFFactory(comsoc.PositionalSWF(comsoc.KApprovalVector(nbAlts, nbAlts-1)), comsoc.TieBreakFactory(tieBreak))
+			ranking, err := swf(votes.Profile)
+			if err != nil {
+				return comsoc.RuleResult{}, err
+			}
+			return comsoc.RankingResult(ranking)
+		},
+	}
+	err := restagent.RegisterRule(veto)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Available rules:", restagent.Rules)
+
+	server := restserveragent.NewRestServerAgent(endpoints.ServerPort)
+	server.Start()
+	fmt.Scanln()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+// Measures how manipulable the registered rules are: for random profiles (each voter drawing a ranking
+// uniformly), counts the profiles where a coalition of at most k voters can obtain a winner they all prefer.
+// Rules needing options or other votes than rankings are skipped
+func main() {
+	var nbVoters int
+	var nbAlts int
+	var nbProfiles int
+	var k int
+
+	fmt.Println("How many voters ?")
+	fmt.Scanln(&nbVoters)
+	fmt.Println("How many alternatives ?")
+	fmt.Scanln(&nbAlts)
+	fmt.Println("How many profiles ?")
+	fmt.Scanln(&nbProfiles)
+	fmt.Println("Size of the coalitions ?")
+	fmt.Scanln(&k)
+	if nbVoters < 1 || nbAlts < 2 || nbProfiles < 1 || k < 1 {
+		fmt.Println("at least 1 voter, 2 alternatives, 1 profile and coalitions of 1 voter are needed")
+		return
+	}
+
+	// The same profiles are used for all the rules
+	rng := rand.New(rand.NewSource(1))
+	alts := make([]comsoc.Alternative, nbAlts)
+	for i := range alts {
+		alts[i] = comsoc.Alternative(i + 1)
+	}
+	profiles := make([]comsoc.Profile, nbProfiles)
+	for i := range profiles {
+		profiles[i] = make(comsoc.Profile, nbVoters)
+		for v := range profiles[i] {
+			for _, j := range rng.Perm(nbAlts) {
+				profiles[i][v] = append(profiles[i][v], alts[j])
+			}
+		}
+	}
+
+	for _, name := range restagent.Rules {
+		rule, _ := restagent.LookupRule(name)
+		if rule.Format() != comsoc.RankingFormat || rule.CheckOptions(comsoc.RuleOptions{}, nbAlts) != nil {
+			continue
+		}
+		winner := comsoc.RuleWinner(rule, comsoc.RuleOptions{}, alts, alts)
+		var nbManipulable int
+		var exhaustive = true
+		for _, p := range profiles {
+			manipulation, ex, err := comsoc.FindManipulation(p, winner, k)
+			if err != nil {
+				fmt.Printf("%-22s error: %s\n", name, err.Error())
+				break
+			}
+			exhaustive = exhaustive && ex
+			if manipulation != nil {
+				nbManipulable++
+			}
+		}
+		note := ""
+		if !exhaustive {
+			note = " (at least: only compromising and burying were tried for some coalitions, or the search budget ran out)"
+		}
+		fmt.Printf("%-22s %5.1f%% of the profiles are manipulable%s\n", name, 100*float64(nbManipulable)/float64(nbProfiles), note)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc/preflib"
+)
+
+// Computes every registered rule on the PrefLib file given as argument (soc, soi, toc, toi or cat),
+// the ties being broken in increasing order of the alternatives. Orders with ties or unranked alternatives
+// are given to the rules accepting partial votes, categories to approval (the first category being approved),
+// and the rules needing grades are skipped
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Println("usage: launch-preflib FILE")
+		return
+	}
+	data, err := preflib.ReadFile(os.Args[1])
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	alts := data.Alts()
+	if data.Title == "" {
+		data.Title = os.Args[1]
+	}
+	fmt.Printf("%s: %d voters, %d alternatives (%s)\n\n", data.Title, len(data.Votes), data.NbAlts, data.Type)
+
+	for _, name := range restagent.Rules {
+		rule, _ := restagent.LookupRule(name)
+		options := defaultOptions(name, data.NbAlts)
+		votes := comsoc.Votes{Alts: alts}
+		switch rule.Format() {
+		case comsoc.RankingFormat:
+			if data.InferType() == preflib.SOC {
+				votes.Profile, _ = data.Profile()
+			} else {
+				options.AllowPartial = true
+				votes.Weak = data.Votes
+			}
+		case comsoc.ApprovalFormat:
+			votes.Profile, votes.Thresholds, err = data.Approval()
+		case comsoc.GradeFormat:
+			err = fmt.Errorf("grades are needed")
+		}
+		if err == nil {
+			err = rule.CheckOptions(options, data.NbAlts)
+		}
+		if err != nil {
+			fmt.Printf("%-22s skipped: %s\n", name, err.Error())
+			err = nil
+			continue
+		}
+		res, err := rule.Compute(votes, options, alts)
+		if err != nil {
+			fmt.Printf("%-22s error: %s\n", name, err.Error())
+			err = nil
+			continue
+		}
+		if res.Committee != nil {
+			fmt.Printf("%-22s committee %v\n", name, res.Committee)
+		} else {
+			fmt.Printf("%-22s winner %d, ranking %v\n", name, res.Winner, res.Ranking)
+		}
+	}
+}
+
+// Options of the rules needing some, as for the generated agents
+func defaultOptions(rule string, nbAlts int) comsoc.RuleOptions {
+	options := comsoc.RuleOptions{}
+	if rule == restagent.Scoring {
+		options.ScoreVector = comsoc.DowdallVector(nbAlts)
+	}
+	if restagent.ContainsRule(restagent.CommitteeRules, rule) {
+		options.Seats = (nbAlts + 1) / 2
+	}
+	if rule == restagent.CondorcetCompletion {
+		options.Fallback = restagent.Schulze
+	}
+	return options
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc/generator"
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/instances"
 )
 
@@ -11,6 +13,9 @@ func main() {
 	var nbAgents int
 	var nbBallot int
 	var nbAlts int
+	var model string
+	var param float64
+	var seed int64
 
 	fmt.Println("How many voting agents ?")
 	fmt.Scanln(&nbAgents)
@@ -18,6 +23,26 @@ func main() {
 	fmt.Scanln(&nbBallot)
 	fmt.Println("How many alternatives ?")
 	fmt.Scanln(&nbAlts)
+	fmt.Printf("Preference model (%s, %s by default) ?\n", strings.Join(generator.Models, ", "), generator.IC)
+	fmt.Scanln(&model)
+	if model == generator.Mallows || model == generator.Urn {
+		fmt.Println("Parameter (phi for mallows, alpha for urn) ?")
+		fmt.Scanln(&param)
+	}
+	fmt.Println("Seed (0 for a random one) ?")
+	fmt.Scanln(&seed)
 
-	instances.LaunchAgents(nbBallot, nbAgents, nbAlts, instances.InitVotingAgents)
+	if model == "" {
+		model = generator.IC
+	}
+	gen, err := generator.New(model, param)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if seed != 0 {
+		instances.SetSeed(seed)
+	}
+
+	instances.LaunchAgents(nbBallot, nbAgents, nbAlts, instances.InitGeneratedVotingAgents(gen))
 }
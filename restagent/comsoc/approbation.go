@@ -5,34 +5,36 @@ import "fmt"
 // ApprovalSWF calculates the social welfare function for the approval voting method.
 // It counts the votes for each alternative up to the threshold for each voter.
 func ApprovalSWF(p Profile, thresholds []int) (count Count, err error) {
-	err = checkProfile(p)
+	wp, wThresholds, err := CompressApproval(p, thresholds)
 	if err != nil {
 		return nil, err
 	}
-	count = make(Count, len(p[0])) //initializing the map
-	for _, alt := range p[0] {
+	return ApprovalWeightedSWF(wp, wThresholds)
+}
+
+// ApprovalWeightedSWF is ApprovalSWF for a weighted profile, thresholds[i] being the threshold of wp.Rankings[i].
+func ApprovalWeightedSWF(wp WeightedProfile, thresholds []int) (count Count, err error) {
+	err = checkWeightedProfile(wp)
+	if err != nil {
+		return nil, err
+	}
+	if len(thresholds) != len(wp.Rankings) {
+		return nil, fmt.Errorf("%d thresholds given for %d rankings", len(thresholds), len(wp.Rankings))
+	}
+	alts := wp.Alternatives()
+	count = make(Count, len(alts)) //initializing the map
+	for _, alt := range alts {
 		// Initialize to 0
 		count[alt] = 0
 	}
 	// Counting the votes of all profiles, from 0 to thresholds[i]
-	for indVoter, voter := range p {
-		// For all recorded votes
-		for i, alt := range voter {
-			// For each alternative of a vote
-			if thresholds[indVoter] < 0 || thresholds[indVoter] > len(voter) {
-				return nil, fmt.Errorf("threshold %d is incorrect with %d alternatives", thresholds[indVoter], len(voter))
-			}
-			if i < thresholds[indVoter] {
-				// If we count this alternative
-				_, ok := count[alt]
-				if !ok {
-					count[alt] = 1
-				} else {
-					count[alt]++
-				}
-			} else {
-				break
-			}
+	for indVoter, voter := range wp.Rankings {
+		if thresholds[indVoter] < 0 || thresholds[indVoter] > len(voter) {
+			return nil, fmt.Errorf("threshold %d is incorrect with %d alternatives", thresholds[indVoter], len(voter))
+		}
+		// For each approved alternative of a vote
+		for _, alt := range voter[:thresholds[indVoter]] {
+			count[alt] += wp.Weights[indVoter]
 		}
 	}
 	return count, nil
@@ -47,3 +49,12 @@ func ApprovalSCF(p Profile, thresholds []int) (bestAlts []Alternative, err error
 	}
 	return maxCount(count), nil
 }
+
+// ApprovalWeightedSCF is ApprovalSCF for a weighted profile.
+func ApprovalWeightedSCF(wp WeightedProfile, thresholds []int) (bestAlts []Alternative, err error) {
+	count, err := ApprovalWeightedSWF(wp, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
@@ -0,0 +1,337 @@
+package comsoc
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+* Approval-based committee rules
+* Each voter approves the first thresholds[i] alternatives of their preferences (as in ApprovalSWF)
+* and a committee of seats alternatives is elected
+* - Proportional Approval Voting (PAV): the committee maximizes the sum over the voters of
+*   1 + 1/2 + ... + 1/k, where k is the number of approved members of the committee
+* - Sequential PAV: members are added one at a time, maximizing the increase of the PAV score
+* - Sequential Phragmén: members are added one at a time, each one bringing a load of 1 shared
+*   by its approvers, choosing the member which minimizes the maximal load of the voters
+* - Chamberlin-Courant (approval): the committee maximizes the number of voters approving
+*   at least one of its members
+* PAV and Chamberlin-Courant are computed exhaustively when there are at most CommitteeExactLimit
+* committees to enumerate. Beyond, PAV falls back to Sequential PAV and Chamberlin-Courant to a
+* greedy algorithm adding the member which covers the most voters not covered yet.
+* The tie-break is used to choose among equivalent committees
+ */
+
+// Precision used to compare the scores of committees
+const committeeEpsilon = 1e-9
+
+// Maximum number of committees enumerated by the exact solvers of PAV and Chamberlin-Courant
+const CommitteeExactLimit = 100000
+
+// Tells whether the number of committees of size seats among nbCandidates, C(nbCandidates, seats),
+// is at most CommitteeExactLimit
+func committeeExact(nbCandidates int, seats int) bool {
+	if seats > nbCandidates-seats {
+		seats = nbCandidates - seats
+	}
+	nb := 1
+	for i := 1; i <= seats; i++ {
+		// nb is C(nbCandidates-seats+i, i), always an integer
+		nb = nb * (nbCandidates - seats + i) / i
+		if nb > CommitteeExactLimit {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the set of approved alternatives of each voter
+// (for each ranking of the weighted profile, thresholds[i] being the threshold of wp.Rankings[i])
+func approvalSets(wp WeightedProfile, thresholds []int) ([]map[Alternative]bool, error) {
+	err := checkWeightedProfile(wp)
+	if err != nil {
+		return nil, err
+	}
+	if len(thresholds) != len(wp.Rankings) {
+		return nil, fmt.Errorf("%d thresholds given for %d rankings", len(thresholds), len(wp.Rankings))
+	}
+	sets := make([]map[Alternative]bool, len(wp.Rankings))
+	for i, votant := range wp.Rankings {
+		if thresholds[i] < 0 || thresholds[i] > len(votant) {
+			return nil, fmt.Errorf("threshold %d is incorrect with %d alternatives", thresholds[i], len(votant))
+		}
+		sets[i] = make(map[Alternative]bool, thresholds[i])
+		for _, alt := range votant[:thresholds[i]] {
+			sets[i][alt] = true
+		}
+	}
+	return sets, nil
+}
+
+// Checks the number of seats and returns the alternatives ordered by the tie-break
+func committeeCandidates(wp WeightedProfile, seats int, tieBreak []Alternative) ([]Alternative, error) {
+	alts := wp.Alternatives()
+	if seats < 1 || seats > len(alts) {
+		return nil, fmt.Errorf("%d seats cannot be filled with %d candidates", seats, len(alts))
+	}
+	order := tieBreakOrder(alts, tieBreak)
+	candidates := make([]Alternative, len(order))
+	for i, ind := range order {
+		candidates[i] = alts[ind]
+	}
+	return candidates, nil
+}
+
+// Returns the committee of size seats maximizing score. Committees are enumerated in the
+// lexicographic order of the candidates, so that the first best one is kept
+func bestCommittee(candidates []Alternative, seats int, score func([]Alternative) float64) []Alternative {
+	var best []Alternative
+	var bestScore float64
+	indexes := make([]int, seats)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	committee := make([]Alternative, seats)
+	for {
+		for i, ind := range indexes {
+			committee[i] = candidates[ind]
+		}
+		if s := score(committee); best == nil || s > bestScore+committeeEpsilon {
+			best = append([]Alternative{}, committee...)
+			bestScore = s
+		}
+		// Next combination
+		i := seats - 1
+		for i >= 0 && indexes[i] == len(candidates)-seats+i {
+			i--
+		}
+		if i < 0 {
+			return best
+		}
+		indexes[i]++
+		for j := i + 1; j < seats; j++ {
+			indexes[j] = indexes[j-1] + 1
+		}
+	}
+}
+
+// Number of approved members of the committee for each voter
+func nbApproved(sets []map[Alternative]bool, committee []Alternative) []int {
+	res := make([]int, len(sets))
+	for i, set := range sets {
+		for _, alt := range committee {
+			if set[alt] {
+				res[i]++
+			}
+		}
+	}
+	return res
+}
+
+// Returns the PAV committee, and whether it has been computed by the exact solver
+// (otherwise it is the Sequential PAV committee)
+func PAVCommittee(p Profile, thresholds []int, seats int, tieBreak []Alternative) (committee []Alternative, exact bool, err error) {
+	wp, wThresholds, err := CompressApproval(p, thresholds)
+	if err != nil {
+		return nil, false, err
+	}
+	return PAVWeightedCommittee(wp, wThresholds, seats, tieBreak)
+}
+
+func PAVWeightedCommittee(wp WeightedProfile, thresholds []int, seats int, tieBreak []Alternative) (committee []Alternative, exact bool, err error) {
+	sets, err := approvalSets(wp, thresholds)
+	if err != nil {
+		return nil, false, err
+	}
+	candidates, err := committeeCandidates(wp, seats, tieBreak)
+	if err != nil {
+		return nil, false, err
+	}
+	if !committeeExact(len(candidates), seats) {
+		committee, err = SeqPAVWeightedCommittee(wp, thresholds, seats, tieBreak)
+		return committee, false, err
+	}
+	return bestCommittee(candidates, seats, func(committee []Alternative) float64 {
+		var score float64
+		for i, k := range nbApproved(sets, committee) {
+			for j := 1; j <= k; j++ {
+				score += float64(wp.Weights[i]) / float64(j)
+			}
+		}
+		return score
+	}), true, nil
+}
+
+// Returns the Chamberlin-Courant committee, and whether it has been computed by the exact solver
+// (otherwise it is the greedy committee)
+func ChamberlinCourantCommittee(p Profile, thresholds []int, seats int, tieBreak []Alternative) (committee []Alternative, exact bool, err error) {
+	wp, wThresholds, err := CompressApproval(p, thresholds)
+	if err != nil {
+		return nil, false, err
+	}
+	return ChamberlinCourantWeightedCommittee(wp, wThresholds, seats, tieBreak)
+}
+
+func ChamberlinCourantWeightedCommittee(wp WeightedProfile, thresholds []int, seats int, tieBreak []Alternative) (committee []Alternative, exact bool, err error) {
+	sets, err := approvalSets(wp, thresholds)
+	if err != nil {
+		return nil, false, err
+	}
+	candidates, err := committeeCandidates(wp, seats, tieBreak)
+	if err != nil {
+		return nil, false, err
+	}
+	if !committeeExact(len(candidates), seats) {
+		return greedyCCCommittee(sets, wp.Weights, candidates, seats), false, nil
+	}
+	return bestCommittee(candidates, seats, func(committee []Alternative) float64 {
+		var score float64
+		for i, k := range nbApproved(sets, committee) {
+			if k > 0 {
+				score += float64(wp.Weights[i])
+			}
+		}
+		return score
+	}), true, nil
+}
+
+// Greedy approximation of Chamberlin-Courant: members are added one at a time, maximizing the
+// weight of the voters approving none of the members yet (candidates are ordered by the tie-break)
+func greedyCCCommittee(sets []map[Alternative]bool, weights []int, candidates []Alternative, seats int) []Alternative {
+	committee := make([]Alternative, 0, seats)
+	elected := make(map[Alternative]bool, seats)
+	covered := make([]bool, len(sets))
+	for len(committee) < seats {
+		var best Alternative
+		var bestGain = -1
+		for _, alt := range candidates {
+			if elected[alt] {
+				continue
+			}
+			var gain int
+			for i, set := range sets {
+				if !covered[i] && set[alt] {
+					gain += weights[i]
+				}
+			}
+			if gain > bestGain {
+				best, bestGain = alt, gain
+			}
+		}
+		for i, set := range sets {
+			if set[best] {
+				covered[i] = true
+			}
+		}
+		elected[best] = true
+		committee = append(committee, best)
+	}
+	return committee
+}
+
+func SeqPAVCommittee(p Profile, thresholds []int, seats int, tieBreak []Alternative) ([]Alternative, error) {
+	wp, wThresholds, err := CompressApproval(p, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	return SeqPAVWeightedCommittee(wp, wThresholds, seats, tieBreak)
+}
+
+func SeqPAVWeightedCommittee(wp WeightedProfile, thresholds []int, seats int, tieBreak []Alternative) ([]Alternative, error) {
+	sets, err := approvalSets(wp, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := committeeCandidates(wp, seats, tieBreak)
+	if err != nil {
+		return nil, err
+	}
+	committee := make([]Alternative, 0, seats)
+	elected := make(map[Alternative]bool, seats)
+	approved := make([]int, len(sets)) // Number of approved members for each voter
+	for len(committee) < seats {
+		var best Alternative
+		var bestGain = -1.0
+		for _, alt := range candidates {
+			if elected[alt] {
+				continue
+			}
+			var gain float64
+			for i, set := range sets {
+				if set[alt] {
+					gain += float64(wp.Weights[i]) / float64(approved[i]+1)
+				}
+			}
+			// Candidates are ordered by the tie-break, so only a strictly better gain is kept
+			if gain > bestGain+committeeEpsilon {
+				best, bestGain = alt, gain
+			}
+		}
+		for i, set := range sets {
+			if set[best] {
+				approved[i]++
+			}
+		}
+		elected[best] = true
+		committee = append(committee, best)
+	}
+	return committee, nil
+}
+
+func PhragmenCommittee(p Profile, thresholds []int, seats int, tieBreak []Alternative) ([]Alternative, error) {
+	wp, wThresholds, err := CompressApproval(p, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	return PhragmenWeightedCommittee(wp, wThresholds, seats, tieBreak)
+}
+
+func PhragmenWeightedCommittee(wp WeightedProfile, thresholds []int, seats int, tieBreak []Alternative) ([]Alternative, error) {
+	sets, err := approvalSets(wp, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := committeeCandidates(wp, seats, tieBreak)
+	if err != nil {
+		return nil, err
+	}
+	committee := make([]Alternative, 0, seats)
+	elected := make(map[Alternative]bool, seats)
+	loads := make([]float64, len(sets))
+	for len(committee) < seats {
+		var best Alternative
+		var bestLoad = math.Inf(1)
+		var found = false
+		for _, alt := range candidates {
+			if elected[alt] {
+				continue
+			}
+			// Maximal load of the approvers if alt is elected (infinite if nobody approves alt)
+			var nbApprovers int
+			var sumLoads float64
+			for i, set := range sets {
+				if set[alt] {
+					nbApprovers += wp.Weights[i]
+					sumLoads += float64(wp.Weights[i]) * loads[i]
+				}
+			}
+			var load = math.Inf(1)
+			if nbApprovers > 0 {
+				load = (1 + sumLoads) / float64(nbApprovers)
+			}
+			if !found || load < bestLoad-committeeEpsilon {
+				best, bestLoad, found = alt, load, true
+			}
+		}
+		if !math.IsInf(bestLoad, 1) {
+			for i, set := range sets {
+				if set[best] {
+					loads[i] = bestLoad
+				}
+			}
+		}
+		elected[best] = true
+		committee = append(committee, best)
+	}
+	return committee, nil
+}
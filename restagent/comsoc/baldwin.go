@@ -0,0 +1,43 @@
+package comsoc
+
+/*
+* Baldwin Method
+* In each round, the Borda scores are computed on the remaining candidates
+* and the candidate with the lowest score is eliminated
+* The tie-break is used within the rounds to choose which candidate is eliminated
+ */
+
+func BaldwinSWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error) {
+	return BaldwinWeightedSWF_TieBreak(Compress(p), tieBreak)
+}
+
+func BaldwinWeightedSWF_TieBreak(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, error) {
+	ranking, _, err := BaldwinWeightedExplain(wp, tieBreak)
+	return ranking, err
+}
+
+// Same as BaldwinSWF_TieBreak, with the trace of the rounds
+func BaldwinExplain(p Profile, tieBreak []Alternative) ([]Alternative, Explanation, error) {
+	return BaldwinWeightedExplain(Compress(p), tieBreak)
+}
+
+func BaldwinWeightedExplain(wp WeightedProfile, tieBreak []Alternative) (ranking []Alternative, explanation Explanation, err error) {
+	err = checkWeightedProfile(wp)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining := wp // removeAlternatives returns a new profile, so wp is not modified
+	eliminated := make([]Alternative, 0, len(wp.Alternatives()))
+	for len(remaining.Alternatives()) > 1 {
+		count, err := BordaWeightedSWF(remaining)
+		if err != nil {
+			return nil, nil, err
+		}
+		worstAlts := minCount(count)
+		worst := worstByTieBreak(worstAlts, tieBreak)
+		explanation.addRound("borda score", count, nil, []Alternative{worst}, len(worstAlts) > 1)
+		eliminated = append(eliminated, worst)
+		remaining = removeAlternatives(remaining, worst)
+	}
+	return eliminationRanking(remaining.Alternatives(), eliminated), explanation, nil
+}
@@ -88,3 +88,60 @@ func checkProfileAlternative(prefs Profile, alts []Alternative) error {
 	}
 	return nil
 }
+
+// Returns the best alternatives for a given float count
+func maxFloatCount(count FloatCount) (bestAlts []Alternative) {
+	for i, v := range count {
+		if len(bestAlts) == 0 || v > count[bestAlts[0]] {
+			bestAlts = []Alternative{i}
+		} else if v == count[bestAlts[0]] {
+			bestAlts = append(bestAlts, i)
+		}
+	}
+	return
+}
+
+// Returns the best alternative among alts according to the tie-break (the first one in the tie-break)
+func bestByTieBreak(alts []Alternative, tieBreak []Alternative) Alternative {
+	var best = alts[0]
+	for _, alt := range alts[1:] {
+		if r := rank(alt, tieBreak); r != -1 && (rank(best, tieBreak) == -1 || r < rank(best, tieBreak)) {
+			best = alt
+		}
+	}
+	return best
+}
+
+// Returns the worst alternative among alts according to the tie-break (the last one in the tie-break)
+func worstByTieBreak(alts []Alternative, tieBreak []Alternative) Alternative {
+	var worst = alts[0]
+	for _, alt := range alts[1:] {
+		if r := rank(worst, tieBreak); r != -1 && (rank(alt, tieBreak) == -1 || rank(alt, tieBreak) > r) {
+			worst = alt
+		}
+	}
+	return worst
+}
+
+// Returns the alternatives with the lowest score in count
+func minCount(count Count) (worstAlts []Alternative) {
+	for alt, v := range count {
+		if len(worstAlts) == 0 || v < count[worstAlts[0]] {
+			worstAlts = []Alternative{alt}
+		} else if v == count[worstAlts[0]] {
+			worstAlts = append(worstAlts, alt)
+		}
+	}
+	return
+}
+
+// Builds a ranking from the alternatives chosen first (best first)
+// and the alternatives eliminated (worst first)
+func eliminationRanking(chosen []Alternative, eliminated []Alternative) []Alternative {
+	res := make([]Alternative, 0, len(chosen)+len(eliminated))
+	res = append(res, chosen...)
+	for i := len(eliminated) - 1; i >= 0; i-- {
+		res = append(res, eliminated[i])
+	}
+	return res
+}
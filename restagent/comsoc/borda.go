@@ -2,31 +2,35 @@ package comsoc
 
 // Borda Method
 func BordaSWF(p Profile) (Count, error) {
-	err := checkProfile(p)
+	return BordaWeightedSWF(Compress(p))
+}
+
+func BordaWeightedSWF(wp WeightedProfile) (Count, error) {
+	err := checkWeightedProfile(wp)
 	if err != nil {
 		return nil, err
 	}
-	count := make(Count, len(p[0])) // Initialize the map
-	for _, alt := range p[0] {
+	alts := wp.Alternatives()
+	count := make(Count, len(alts)) // Initialize the map
+	for _, alt := range alts {
 		count[alt] = 0
 	}
-	// Counting votes from the profile
-	var nbAlt = len(p[0])
-	for _, votant := range p {
+	// Counting votes from the profile, each ranking counting as many times as its weight
+	var nbAlt = len(alts)
+	for r, votant := range wp.Rankings {
 		for i, alt := range votant {
-			_, ok := count[alt]
-			if !ok {
-				count[alt] = nbAlt - 1 - i
-			} else {
-				count[alt] += nbAlt - 1 - i
-			}
+			count[alt] += (nbAlt - 1 - i) * wp.Weights[r]
 		}
 	}
 	return count, nil
 }
 
 func BordaSCF(p Profile) (bestAlts []Alternative, err error) {
-	count, err := BordaSWF(p)
+	return BordaWeightedSCF(Compress(p))
+}
+
+func BordaWeightedSCF(wp WeightedProfile) (bestAlts []Alternative, err error) {
+	count, err := BordaWeightedSWF(wp)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,64 @@
+package comsoc
+
+import "fmt"
+
+/*
+* Bucklin Method
+* First, only the first choices of the voters are counted. If a candidate
+* gets a strict majority, the candidate with the most votes is elected
+* Otherwise the second choices are added, then the third ones, and so on
+* To get a complete ranking, the elected candidate is removed and the process
+* starts again with the remaining candidates
+* The tie-break is used within the rounds to choose between candidates with the same count
+ */
+
+func BucklinSWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error) {
+	return BucklinWeightedSWF_TieBreak(Compress(p), tieBreak)
+}
+
+func BucklinWeightedSWF_TieBreak(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, error) {
+	ranking, _, err := BucklinWeightedExplain(wp, tieBreak)
+	return ranking, err
+}
+
+// Same as BucklinSWF_TieBreak, with the trace of the rounds
+func BucklinExplain(p Profile, tieBreak []Alternative) ([]Alternative, Explanation, error) {
+	return BucklinWeightedExplain(Compress(p), tieBreak)
+}
+
+func BucklinWeightedExplain(wp WeightedProfile, tieBreak []Alternative) (ranking []Alternative, explanation Explanation, err error) {
+	err = checkWeightedProfile(wp)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining := wp // removeAlternatives returns a new profile, so wp is not modified
+	nbVoters := wp.NbVoters()
+	elected := make([]Alternative, 0, len(wp.Alternatives()))
+	for len(remaining.Alternatives()) > 1 {
+		count := make(Count, len(remaining.Alternatives()))
+		for _, alt := range remaining.Alternatives() {
+			count[alt] = 0
+		}
+		// Add the k-th choices until a candidate gets a strict majority
+		// (it always happens when all the choices are counted)
+		for k := 0; k < len(remaining.Alternatives()); k++ {
+			for r, votant := range remaining.Rankings {
+				count[votant[k]] += remaining.Weights[r]
+			}
+			bestAlts := maxCount(count)
+			if nb := count[bestAlts[0]]; nb > nbVoters-nb {
+				winner := bestByTieBreak(bestAlts, tieBreak)
+				var criterion = "first places"
+				if k > 0 {
+					criterion = fmt.Sprintf("first %d places", k+1)
+				}
+				explanation.addRound(criterion, count, []Alternative{winner}, nil, len(bestAlts) > 1)
+				elected = append(elected, winner)
+				remaining = removeAlternatives(remaining, winner)
+				break
+			}
+		}
+	}
+	elected = append(elected, remaining.Alternatives()[0])
+	return elected, explanation, nil
+}
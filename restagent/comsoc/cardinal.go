@@ -0,0 +1,148 @@
+package comsoc
+
+import (
+	"errors"
+	"sort"
+)
+
+/*
+* Cardinal ballots
+* Each voter gives a grade to every alternative, within the scale of the ballot
+* - Range voting: the alternatives are ranked by the sum of their grades
+* - STAR (Score Then Automatic Runoff): the two alternatives with the highest sums
+*   go to a runoff, won by the one graded higher by the most voters
+* - Majority Judgment: the alternatives are ranked by their median grade. Ties are
+*   broken by removing one median grade at a time and comparing the new medians
+ */
+
+// Checks the given grade profile, e.g., that every voter grades the same alternatives
+func checkGradeProfile(g GradeProfile) error {
+	if len(g) < 1 {
+		return errors.New("no votes submitted")
+	}
+	if len(g[0]) < 2 {
+		return errors.New("less than 2 candidates")
+	}
+	for _, grades := range g {
+		if len(grades) != len(g[0]) {
+			return errors.New("grade profile is not complete")
+		}
+		for alt := range g[0] {
+			if _, ok := grades[alt]; !ok {
+				return errors.New("grade profile is not correct: a alternative is missing")
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the graded alternatives, ordered by the tie-break
+func gradedAlternatives(g GradeProfile, tieBreak []Alternative) []Alternative {
+	alts := make([]Alternative, 0, len(g[0]))
+	for alt := range g[0] {
+		alts = append(alts, alt)
+	}
+	sort.Slice(alts, func(i, j int) bool { return alts[i] < alts[j] })
+	res := make([]Alternative, len(alts))
+	for i, ind := range tieBreakOrder(alts, tieBreak) {
+		res[i] = alts[ind]
+	}
+	return res
+}
+
+// Range Voting
+func RangeSWF(g GradeProfile) (Count, error) {
+	err := checkGradeProfile(g)
+	if err != nil {
+		return nil, err
+	}
+	count := make(Count, len(g[0]))
+	for _, grades := range g {
+		for alt, grade := range grades {
+			count[alt] += grade
+		}
+	}
+	return count, nil
+}
+
+func RangeSCF(g GradeProfile) (bestAlts []Alternative, err error) {
+	count, err := RangeSWF(g)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
+
+// Ranking of Range voting, alternatives with the same score being ordered by the tie-break
+func RangeSWF_TieBreak(g GradeProfile, tieBreak []Alternative) ([]Alternative, error) {
+	count, err := RangeSWF(g)
+	if err != nil {
+		return nil, err
+	}
+	res := gradedAlternatives(g, tieBreak)
+	sort.SliceStable(res, func(i, j int) bool { return count[res[i]] > count[res[j]] })
+	return res, nil
+}
+
+// Note: the tie-break is used both for the scores and for the runoff
+func STARSWF_TieBreak(g GradeProfile, tieBreak []Alternative) ([]Alternative, error) {
+	res, err := RangeSWF_TieBreak(g, tieBreak)
+	if err != nil {
+		return nil, err
+	}
+
+	// Automatic runoff between the two best alternatives
+	var first, second int
+	for _, grades := range g {
+		if grades[res[0]] > grades[res[1]] {
+			first++
+		} else if grades[res[0]] < grades[res[1]] {
+			second++
+		}
+	}
+	// If the runoff is tied, the alternative with the highest score wins (then the tie-break)
+	if second > first {
+		res[0], res[1] = res[1], res[0]
+	}
+	return res, nil
+}
+
+// Returns the successive medians obtained by removing one median grade at a time
+func majorityValue(g GradeProfile, alt Alternative) []int {
+	grades := make([]int, len(g))
+	for i, voterGrades := range g {
+		grades[i] = voterGrades[alt]
+	}
+	sort.Ints(grades)
+	res := make([]int, 0, len(grades))
+	for len(grades) > 0 {
+		// Lower median
+		med := (len(grades) - 1) / 2
+		res = append(res, grades[med])
+		grades = append(grades[:med], grades[med+1:]...)
+	}
+	return res
+}
+
+func MajorityJudgmentSWF_TieBreak(g GradeProfile, tieBreak []Alternative) ([]Alternative, error) {
+	err := checkGradeProfile(g)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[Alternative][]int, len(g[0]))
+	for alt := range g[0] {
+		values[alt] = majorityValue(g, alt)
+	}
+	res := gradedAlternatives(g, tieBreak)
+	// Lexicographic comparison of the majority values, alternatives with the same value stay in the tie-break order
+	sort.SliceStable(res, func(i, j int) bool {
+		vi, vj := values[res[i]], values[res[j]]
+		for k := range vi {
+			if vi[k] != vj[k] {
+				return vi[k] > vj[k]
+			}
+		}
+		return false
+	})
+	return res, nil
+}
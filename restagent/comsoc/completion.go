@@ -0,0 +1,32 @@
+package comsoc
+
+/*
+* Condorcet-consistent completion rules
+* The Condorcet winner is elected when it exists, otherwise the result of a fallback rule is used
+* (the fallback being given by the registered rules, see condorcetCompletionRule in registry.go):
+* - Black's rule: fallback on Borda
+* - Condorcet-IRV: fallback on STV (Instant-Runoff Voting)
+* The fallback rule is only computed when there is no Condorcet winner and then gives the ranking.
+* Otherwise, the Condorcet winner comes first and the other alternatives follow in the order of the tie-break
+ */
+
+// Returns the ranking, and true if it has been decided by the Condorcet winner (false if decided by the fallback rule)
+func CondorcetCompletion(p Profile, fallback func(Profile) ([]Alternative, error), tieBreak []Alternative) (ranking []Alternative, condorcet bool, err error) {
+	winner, err := CondorcetWinner(p)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(winner) == 0 {
+		ranking, err = fallback(p)
+		return ranking, false, err
+	}
+	alts := p[0]
+	ranking = make([]Alternative, 0, len(alts))
+	ranking = append(ranking, winner[0])
+	for _, ind := range tieBreakOrder(alts, tieBreak) {
+		if alts[ind] != winner[0] {
+			ranking = append(ranking, alts[ind])
+		}
+	}
+	return ranking, true, nil
+}
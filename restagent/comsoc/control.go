@@ -0,0 +1,485 @@
+package comsoc
+
+import (
+	"errors"
+	"sort"
+)
+
+/*
+* Bribery and control
+* An attack changes the election so that a target alternative wins (constructive) or does not win
+* (destructive), at the smallest cost:
+* - bribery: the ballots of k voters are changed
+* - control by adding voters (taken from a pool of unregistered voters) or deleting voters
+* - control by adding candidates (taken from spoilers, which are ranked by the voters but not
+*   candidates at first) or deleting candidates (other than the target)
+* - cloning: k clones are added, each just below the alternative it clones in every ranking
+*   (a clone of the target counts as the target)
+* The costs are tried in increasing order. For each cost, a greedy attack is tried first, then all
+* the attacks of this cost (up to the order of the voters giving the same ranking) as long as the
+* rule has been computed at most ControlSearchLimit times. The attack found is exact if all the
+* attacks of smaller costs were tried.
+* The modified profile is returned, so that the attack can be replayed (see RelabelProfile)
+ */
+
+// Above this number of computations of the winner, only the greedy attacks are tried
+const ControlSearchLimit = 20000
+
+// Kinds of attacks
+const (
+	AttackBribery          = "bribery"
+	AttackAddVoters        = "add-voters"
+	AttackDeleteVoters     = "delete-voters"
+	AttackAddCandidates    = "add-candidates"
+	AttackDeleteCandidates = "delete-candidates"
+	AttackCloning          = "cloning"
+)
+
+// Attack making the target win (or lose, if not constructive)
+type Attack struct {
+	Kind         string        `json:"kind"`
+	Target       Alternative   `json:"target"`
+	Constructive bool          `json:"constructive"`         // True if the target should win, false if it should lose
+	Cost         int           `json:"cost"`                 // Number of ballots changed, voters or candidates added or deleted, or clones added
+	Exact        bool          `json:"exact"`                // True if all the attacks of smaller cost were tried, so that none of them succeeds
+	Voters       []int         `json:"voters,omitempty"`     // Voters bribed or deleted (indices in the profile), or added (indices in the pool)
+	Candidates   []Alternative `json:"candidates,omitempty"` // Candidates added or deleted, or cloned (once per clone)
+	Profile      Profile       `json:"profile"`              // Modified profile
+	Winner       Alternative   `json:"winner"`               // Winner of the modified profile
+}
+
+// Winner of a rule on a profile, whose alternatives are the ones of its rankings
+// (the alternatives which are not in tieBreak, e.g. clones, come last in the tie-break)
+func ProfileWinner(rule Rule, options RuleOptions, tieBreak []Alternative) func(Profile) (Alternative, error) {
+	return func(p Profile) (Alternative, error) {
+		if len(p) == 0 {
+			return 0, errors.New("no votes submitted")
+		}
+		alts := append([]Alternative{}, p[0]...)
+		sort.Slice(alts, func(i, j int) bool { return alts[i] < alts[j] })
+		var tb []Alternative
+		for _, alt := range completeTieBreak(tieBreak, alts) {
+			if rank(alt, alts) >= 0 {
+				tb = append(tb, alt)
+			}
+		}
+		res, err := rule.Compute(Votes{Alts: alts, Profile: p}, options, tb)
+		return res.Winner, err
+	}
+}
+
+// Profile whose alternatives are relabelled 1..m (in increasing order), as expected by the server,
+// labels[i] being the alternative relabelled i+1
+func RelabelProfile(p Profile) (res Profile, labels []Alternative) {
+	if len(p) == 0 {
+		return Profile{}, nil
+	}
+	labels = append([]Alternative{}, p[0]...)
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+	res = make(Profile, len(p))
+	for i, ranking := range p {
+		res[i] = make([]Alternative, len(ranking))
+		for j, alt := range ranking {
+			res[i][j] = Alternative(rank(alt, labels) + 1)
+		}
+	}
+	return res, labels
+}
+
+// State of the search of an attack
+type attackSearch struct {
+	winner       func(Profile) (Alternative, error)
+	target       Alternative
+	constructive bool
+	clones       map[Alternative]Alternative // cloned alternative of each clone
+	budget       int                         // remaining computations of the winner
+	exhaustive   bool                        // true while all the attacks of the costs tried were tried
+}
+
+func newAttackSearch(winner func(Profile) (Alternative, error), target Alternative, constructive bool) *attackSearch {
+	return &attackSearch{winner: winner, target: target, constructive: constructive, budget: ControlSearchLimit, exhaustive: true}
+}
+
+// Returns the winner of p if the attack succeeds on it, 0 otherwise
+func (s *attackSearch) succeeds(p Profile) (Alternative, error) {
+	s.budget--
+	w, err := s.winner(p)
+	if err != nil {
+		return 0, err
+	}
+	won := w == s.target || (w != 0 && s.clones[w] == s.target)
+	if won == s.constructive {
+		return w, nil
+	}
+	return 0, nil
+}
+
+// Checks that the attack makes sense, and returns an attack of cost 0 if the target already wins (or loses)
+func (s *attackSearch) start(kind string, p Profile) (*Attack, error) {
+	err := checkProfile(p)
+	if err != nil {
+		return nil, err
+	}
+	if rank(s.target, p[0]) < 0 {
+		return nil, errors.New("the target is not an alternative of the profile")
+	}
+	w, err := s.succeeds(p)
+	if err != nil || w == 0 {
+		return nil, err
+	}
+	return s.attack(kind, 0, p, w), nil
+}
+
+func (s *attackSearch) attack(kind string, cost int, p Profile, w Alternative) *Attack {
+	return &Attack{Kind: kind, Target: s.target, Constructive: s.constructive, Cost: cost, Exact: s.exhaustive, Profile: p, Winner: w}
+}
+
+// Voters of p sorted so that the first ones help the target most when they are changed:
+// the ones ranking it lowest relative to rival if constructive, highest otherwise
+func (s *attackSearch) votersToChange(p Profile, rival Alternative) []int {
+	order := make([]int, len(p))
+	for i := range order {
+		order[i] = i
+	}
+	help := func(v int) int {
+		if s.constructive {
+			return rank(s.target, p[v]) - rank(rival, p[v])
+		}
+		return rank(rival, p[v]) - rank(s.target, p[v])
+	}
+	sort.SliceStable(order, func(i, j int) bool { return help(order[i]) > help(order[j]) })
+	return order
+}
+
+// Voters of p sorted by the rank they give to target: the ones ranking it lowest first if lowestFirst,
+// highest first otherwise
+func votersByRank(p Profile, target Alternative, lowestFirst bool) []int {
+	order := make([]int, len(p))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if lowestFirst {
+			return rank(target, p[order[i]]) > rank(target, p[order[j]])
+		}
+		return rank(target, p[order[i]]) < rank(target, p[order[j]])
+	})
+	return order
+}
+
+// Ranking where first comes first and last comes last, the other alternatives keeping their order
+func moveFirstLast(ranking []Alternative, first Alternative, last Alternative) []Alternative {
+	res := []Alternative{first}
+	for _, alt := range ranking {
+		if alt != first && alt != last {
+			res = append(res, alt)
+		}
+	}
+	return append(res, last)
+}
+
+// Cheapest bribery of at most maxCost voters. The greedy attack makes the voters helping the target most
+// rank it first and the rival last (the winner if constructive, each other alternative otherwise), or the reverse
+func Bribery(p Profile, winner func(Profile) (Alternative, error), target Alternative, constructive bool, maxCost int) (*Attack, error) {
+	s := newAttackSearch(winner, target, constructive)
+	if a, err := s.start(AttackBribery, p); a != nil || err != nil {
+		return a, err
+	}
+	sincere, err := winner(p)
+	if err != nil {
+		return nil, err
+	}
+	var rivals []Alternative
+	for _, alt := range p[0] {
+		if (constructive && alt == sincere) || (!constructive && alt != target) {
+			rivals = append(rivals, alt)
+		}
+	}
+	var rankings [][]Alternative
+	if nbPermutations(len(p[0]), ControlSearchLimit) <= ControlSearchLimit {
+		rankings = allRankings(p[0])
+	}
+	groups := groupVoters(p)
+
+	for k := 1; k <= maxCost && k <= len(p); k++ {
+		// greedy attack
+		for _, rival := range rivals {
+			modified := append(Profile{}, p...)
+			voters := s.votersToChange(p, rival)[:k]
+			for _, v := range voters {
+				if constructive {
+					modified[v] = moveFirstLast(p[v], target, rival)
+				} else {
+					modified[v] = moveFirstLast(p[v], rival, target)
+				}
+			}
+			w, err := s.succeeds(modified)
+			if err != nil {
+				return nil, err
+			}
+			if w != 0 {
+				a := s.attack(AttackBribery, k, modified, w)
+				a.Voters = voters
+				return a, nil
+			}
+		}
+		// all the attacks of cost k
+		if rankings == nil || nbMultisets(len(rankings), k, s.budget) > s.budget {
+			s.exhaustive = false
+			continue
+		}
+		var found *Attack
+		_, err := forEachCoalition(groups, k, func(voters []int) (bool, error) {
+			modified := append(Profile{}, p...)
+			idx := make([]int, k)
+			for ok := true; ok; ok = nextIndices(idx, len(rankings)) {
+				if s.budget <= 0 {
+					s.exhaustive = false
+					return true, nil
+				}
+				for i, v := range voters {
+					modified[v] = rankings[idx[i]]
+				}
+				w, err := s.succeeds(modified)
+				if err != nil {
+					return true, err
+				}
+				if w != 0 {
+					found = s.attack(AttackBribery, k, modified, w)
+					found.Voters = voters
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		if found != nil || err != nil {
+			return found, err
+		}
+	}
+	return nil, nil
+}
+
+// Cheapest control by adding at most maxCost voters of pool. The greedy attack adds the voters
+// ranking the target highest if constructive, lowest otherwise
+func AddVoters(p Profile, pool Profile, winner func(Profile) (Alternative, error), target Alternative, constructive bool, maxCost int) (*Attack, error) {
+	s := newAttackSearch(winner, target, constructive)
+	if a, err := s.start(AttackAddVoters, p); a != nil || err != nil {
+		return a, err
+	}
+	order := votersByRank(pool, target, !constructive)
+	return s.changeVoters(AttackAddVoters, groupVoters(pool), order, maxCost, func(voters []int) Profile {
+		modified := append(Profile{}, p...)
+		for _, v := range voters {
+			modified = append(modified, pool[v])
+		}
+		return modified
+	})
+}
+
+// Cheapest control by deleting at most maxCost voters. The greedy attack deletes the voters
+// ranking the target lowest if constructive, highest otherwise
+func DeleteVoters(p Profile, winner func(Profile) (Alternative, error), target Alternative, constructive bool, maxCost int) (*Attack, error) {
+	s := newAttackSearch(winner, target, constructive)
+	if a, err := s.start(AttackDeleteVoters, p); a != nil || err != nil {
+		return a, err
+	}
+	order := votersByRank(p, target, constructive)
+	return s.changeVoters(AttackDeleteVoters, groupVoters(p), order, maxCost, func(voters []int) Profile {
+		deleted := make(map[int]bool, len(voters))
+		for _, v := range voters {
+			deleted[v] = true
+		}
+		var modified Profile
+		for i, ranking := range p {
+			if !deleted[i] {
+				modified = append(modified, ranking)
+			}
+		}
+		return modified
+	})
+}
+
+// Attack by changing (adding or deleting) sets of voters: the first k voters of order for the greedy
+// attack, then all the sets of k voters (up to the order of the voters of a group)
+func (s *attackSearch) changeVoters(kind string, groups [][]int, order []int, maxCost int, change func(voters []int) Profile) (*Attack, error) {
+	try := func(k int, voters []int) (*Attack, error) {
+		modified := change(voters)
+		if len(modified) == 0 {
+			return nil, nil
+		}
+		w, err := s.succeeds(modified)
+		if err != nil || w == 0 {
+			return nil, err
+		}
+		a := s.attack(kind, k, modified, w)
+		a.Voters = voters
+		return a, nil
+	}
+	for k := 1; k <= maxCost && k <= len(order); k++ {
+		if a, err := try(k, order[:k]); a != nil || err != nil {
+			return a, err
+		}
+		var found *Attack
+		_, err := forEachCoalition(groups, k, func(voters []int) (bool, error) {
+			if s.budget <= 0 {
+				s.exhaustive = false
+				return true, nil
+			}
+			a, err := try(k, voters)
+			found = a
+			return a != nil, err
+		})
+		if found != nil || err != nil {
+			return found, err
+		}
+	}
+	return nil, nil
+}
+
+// Cheapest control by adding at most maxCost spoilers, which are ranked by the voters of p but are not
+// candidates at first
+func AddCandidates(p Profile, spoilers []Alternative, winner func(Profile) (Alternative, error), target Alternative, constructive bool, maxCost int) (*Attack, error) {
+	s := newAttackSearch(winner, target, constructive)
+	if rank(target, spoilers) >= 0 {
+		return nil, errors.New("the target should not be a spoiler")
+	}
+	if a, err := s.start(AttackAddCandidates, removeFromProfile(p, spoilers)); a != nil || err != nil {
+		return a, err
+	}
+	return s.changeCandidates(AttackAddCandidates, spoilers, maxCost, func(added []Alternative) Profile {
+		var removed []Alternative
+		for _, alt := range spoilers {
+			if rank(alt, added) < 0 {
+				removed = append(removed, alt)
+			}
+		}
+		return removeFromProfile(p, removed)
+	})
+}
+
+// Cheapest control by deleting at most maxCost candidates (other than the target)
+func DeleteCandidates(p Profile, winner func(Profile) (Alternative, error), target Alternative, constructive bool, maxCost int) (*Attack, error) {
+	s := newAttackSearch(winner, target, constructive)
+	if a, err := s.start(AttackDeleteCandidates, p); a != nil || err != nil {
+		return a, err
+	}
+	var others []Alternative
+	for _, alt := range p[0] {
+		if alt != target {
+			others = append(others, alt)
+		}
+	}
+	if maxCost > len(others)-1 {
+		maxCost = len(others) - 1 // at least 2 candidates remain
+	}
+	return s.changeCandidates(AttackDeleteCandidates, others, maxCost, func(deleted []Alternative) Profile {
+		return removeFromProfile(p, deleted)
+	})
+}
+
+// Attack by changing sets of k candidates among alts, for k from 1 to maxCost
+func (s *attackSearch) changeCandidates(kind string, alts []Alternative, maxCost int, change func(changed []Alternative) Profile) (*Attack, error) {
+	for k := 1; k <= maxCost && k <= len(alts); k++ {
+		// subsets of k alternatives, given by increasing indices in alts
+		idx := make([]int, k)
+		for i := range idx {
+			idx[i] = i
+		}
+		for {
+			if s.budget <= 0 {
+				s.exhaustive = false
+				return nil, nil
+			}
+			changed := make([]Alternative, k)
+			for i, j := range idx {
+				changed[i] = alts[j]
+			}
+			modified := change(changed)
+			w, err := s.succeeds(modified)
+			if err != nil {
+				return nil, err
+			}
+			if w != 0 {
+				a := s.attack(kind, k, modified, w)
+				a.Candidates = changed
+				return a, nil
+			}
+			// next subset
+			i := k - 1
+			for i >= 0 && idx[i] == len(alts)-k+i {
+				i--
+			}
+			if i < 0 {
+				break
+			}
+			idx[i]++
+			for j := i + 1; j < k; j++ {
+				idx[j] = idx[j-1] + 1
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Cheapest cloning attack adding at most maxCost clones. The clones are numbered from the largest
+// alternative + 1, each one coming just below the alternative it clones (and its previous clones)
+func Cloning(p Profile, winner func(Profile) (Alternative, error), target Alternative, constructive bool, maxCost int) (*Attack, error) {
+	s := newAttackSearch(winner, target, constructive)
+	if a, err := s.start(AttackCloning, p); a != nil || err != nil {
+		return a, err
+	}
+	alts := append([]Alternative{}, p[0]...)
+	sort.Slice(alts, func(i, j int) bool { return alts[i] < alts[j] })
+	next := alts[len(alts)-1] + 1
+
+	for k := 1; k <= maxCost; k++ {
+		// multisets of k cloned alternatives, given by non-decreasing indices in alts
+		idx := make([]int, k)
+		for ok := true; ok; ok = nextIndices(idx, len(alts)) {
+			if s.budget <= 0 {
+				s.exhaustive = false
+				return nil, nil
+			}
+			cloned := make([]Alternative, k)
+			s.clones = make(map[Alternative]Alternative, k)
+			clonesOf := make(map[Alternative][]Alternative)
+			for i, j := range idx {
+				cloned[i] = alts[j]
+				clone := next + Alternative(i)
+				s.clones[clone] = alts[j]
+				clonesOf[alts[j]] = append(clonesOf[alts[j]], clone)
+			}
+			modified := make(Profile, len(p))
+			for v, ranking := range p {
+				for _, alt := range ranking {
+					modified[v] = append(modified[v], alt)
+					modified[v] = append(modified[v], clonesOf[alt]...)
+				}
+			}
+			w, err := s.succeeds(modified)
+			if err != nil {
+				return nil, err
+			}
+			if w != 0 {
+				a := s.attack(AttackCloning, k, modified, w)
+				a.Candidates = cloned
+				return a, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Profile without the alternatives alts
+func removeFromProfile(p Profile, alts []Alternative) Profile {
+	res := make(Profile, len(p))
+	for i, ranking := range p {
+		res[i] = make([]Alternative, 0, len(ranking))
+		for _, alt := range ranking {
+			if rank(alt, alts) < 0 {
+				res[i] = append(res[i], alt)
+			}
+		}
+	}
+	return res
+}
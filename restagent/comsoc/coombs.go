@@ -0,0 +1,80 @@
+package comsoc
+
+/*
+* Coombs Method
+* In each round, if a candidate is ranked first by a strict majority of voters, it is elected
+* Otherwise, the candidate ranked last by the most voters is eliminated
+* To get a complete ranking, the process goes on with the remaining candidates
+* after a candidate is elected
+* The tie-break is used within the rounds to choose which candidate is eliminated
+ */
+
+func CoombsSWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error) {
+	return CoombsWeightedSWF_TieBreak(Compress(p), tieBreak)
+}
+
+func CoombsWeightedSWF_TieBreak(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, error) {
+	ranking, _, err := CoombsWeightedExplain(wp, tieBreak)
+	return ranking, err
+}
+
+// Same as CoombsSWF_TieBreak, with the trace of the rounds
+func CoombsExplain(p Profile, tieBreak []Alternative) ([]Alternative, Explanation, error) {
+	return CoombsWeightedExplain(Compress(p), tieBreak)
+}
+
+func CoombsWeightedExplain(wp WeightedProfile, tieBreak []Alternative) (ranking []Alternative, explanation Explanation, err error) {
+	err = checkWeightedProfile(wp)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining := wp // removeAlternatives returns a new profile, so wp is not modified
+	nbVoters := wp.NbVoters()
+	elected := make([]Alternative, 0, len(wp.Alternatives()))
+	eliminated := make([]Alternative, 0, len(wp.Alternatives()))
+	for len(remaining.Alternatives()) > 1 {
+		firsts := make(Count, len(remaining.Alternatives()))
+		lasts := make(Count, len(remaining.Alternatives()))
+		for _, alt := range remaining.Alternatives() {
+			firsts[alt] = 0
+			lasts[alt] = 0
+		}
+		for r, votant := range remaining.Rankings {
+			firsts[votant[0]] += remaining.Weights[r]
+			lasts[votant[len(votant)-1]] += remaining.Weights[r]
+		}
+
+		// At most one candidate can have a strict majority
+		var majority = false
+		for alt, nb := range firsts {
+			if nb > nbVoters-nb {
+				explanation.addRound("first places", firsts, []Alternative{alt}, nil, false)
+				elected = append(elected, alt)
+				remaining = removeAlternatives(remaining, alt)
+				majority = true
+				break
+			}
+		}
+		if majority {
+			continue
+		}
+
+		// Candidates ranked last by the most voters
+		var maxLasts = -1
+		var mostLasts []Alternative
+		for alt, nb := range lasts {
+			if nb > maxLasts {
+				maxLasts = nb
+				mostLasts = []Alternative{alt}
+			} else if nb == maxLasts {
+				mostLasts = append(mostLasts, alt)
+			}
+		}
+		worst := worstByTieBreak(mostLasts, tieBreak)
+		explanation.addRound("last places", lasts, nil, []Alternative{worst}, len(mostLasts) > 1)
+		eliminated = append(eliminated, worst)
+		remaining = removeAlternatives(remaining, worst)
+	}
+	elected = append(elected, remaining.Alternatives()[0])
+	return eliminationRanking(elected, eliminated), explanation, nil
+}
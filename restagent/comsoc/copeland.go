@@ -1,9 +1,5 @@
 package comsoc
 
-import (
-	"errors"
-)
-
 /*
 * Copeland Rule
 * The best candidate is the one who beats the most other candidates
@@ -14,64 +10,106 @@ import (
 * 0 otherwise
 * The elected candidate is the one with the highest Copeland score
  */
-func winCopelandDuel(p Profile, alt1 Alternative, alt2 Alternative) (int, error) {
-	ok := checkProfile(p)
-	if ok != nil {
-		return -2, errors.New("invalid profile")
+func CopelandSWF(p Profile) (Count, error) {
+	return CopelandWeightedSWF(Compress(p))
+}
+
+func CopelandWeightedSWF(wp WeightedProfile) (Count, error) {
+	pm, err := NewPairwiseMatrix(wp)
+	if err != nil {
+		return nil, err
 	}
-	var i int = 0
-	var nbWin = 0
-	for _, votant := range p {
-		for votant[i] != alt1 && votant[i] != alt2 {
-			i++
-		}
-		if votant[i] == alt1 {
-			nbWin++
-		}
-		i = 0
+	return copelandScores(pm), nil
+}
+
+func CopelandSCF(p Profile) (bestAlts []Alternative, err error) {
+	count, err := CopelandSWF(p)
+	if err != nil {
+		return nil, err
 	}
-	if nbWin > len(p)-nbWin {
-		return 1, nil
-	} else if nbWin < len(p)-nbWin {
-		return -1, nil
-	} else {
-		return 0, nil
+	return maxCount(count), nil
+}
+
+func CopelandWeightedSCF(wp WeightedProfile) (bestAlts []Alternative, err error) {
+	count, err := CopelandWeightedSWF(wp)
+	if err != nil {
+		return nil, err
 	}
+	return maxCount(count), nil
 }
 
-func CopelandSWF(p Profile) (Count, error) {
-	ok := checkProfile(p)
-	if ok != nil {
-		return nil, ok
+/*
+* Generalized Copeland Rule (Copeland^alpha)
+* A candidate gets 1 point for each duel won and alpha points for each tied duel
+* (alpha = 0.5 gives the same ranking as the Copeland rule above)
+ */
+func CopelandAlphaSWF(p Profile, alpha float64) (FloatCount, error) {
+	return CopelandAlphaWeightedSWF(Compress(p), alpha)
+}
+
+func CopelandAlphaWeightedSWF(wp WeightedProfile, alpha float64) (FloatCount, error) {
+	pm, err := NewPairwiseMatrix(wp)
+	if err != nil {
+		return nil, err
 	}
-	var i int
-	var j int
-	resMap := make(Count, len(p[0]))
-	for i = 0; i < len(p[0])-1; i++ {
-		for j = i + 1; j < len(p[0]); j++ {
-			win, _ := winCopelandDuel(p, p[0][i], p[0][j])
-			_, ok1 := resMap[p[0][i]]
-			if ok1 {
-				resMap[p[0][i]] += win
-			} else {
-				resMap[p[0][i]] = win
-			}
+	return copelandAlphaScores(pm, alpha), nil
+}
 
-			_, ok2 := resMap[p[0][j]]
-			if ok2 {
-				resMap[p[0][j]] -= win
-			} else {
-				resMap[p[0][j]] = -win
-			}
-		}
+func CopelandAlphaSCF(p Profile, alpha float64) (bestAlts []Alternative, err error) {
+	count, err := CopelandAlphaSWF(p, alpha)
+	if err != nil {
+		return nil, err
 	}
-	return resMap, nil
+	return maxFloatCount(count), nil
 }
 
-func CopelandSCF(p Profile) (bestAlts []Alternative, err error) {
-	count, err := CopelandSWF(p)
+func CopelandAlphaWeightedSCF(wp WeightedProfile, alpha float64) (bestAlts []Alternative, err error) {
+	count, err := CopelandAlphaWeightedSWF(wp, alpha)
 	if err != nil {
 		return nil, err
 	}
-	return maxCount(count), nil
+	return maxFloatCount(count), nil
+}
+
+// Copeland scores (+1 for a won duel, -1 for a lost duel) computed from the pairwise matrix
+func copelandScores(pm *PairwiseMatrix) Count {
+	alts := pm.Alts
+	resMap := make(Count, len(alts))
+	for _, alt := range alts {
+		resMap[alt] = 0
+	}
+	for i := 0; i < len(alts)-1; i++ {
+		for j := i + 1; j < len(alts); j++ {
+			if margin := pm.Duels[i][j] - pm.Duels[j][i]; margin > 0 {
+				resMap[alts[i]]++
+				resMap[alts[j]]--
+			} else if margin < 0 {
+				resMap[alts[i]]--
+				resMap[alts[j]]++
+			}
+		}
+	}
+	return resMap
+}
+
+// Copeland^alpha scores computed from the pairwise matrix
+func copelandAlphaScores(pm *PairwiseMatrix, alpha float64) FloatCount {
+	alts := pm.Alts
+	resMap := make(FloatCount, len(alts))
+	for _, alt := range alts {
+		resMap[alt] = 0
+	}
+	for i := 0; i < len(alts)-1; i++ {
+		for j := i + 1; j < len(alts); j++ {
+			if margin := pm.Duels[i][j] - pm.Duels[j][i]; margin > 0 {
+				resMap[alts[i]]++
+			} else if margin < 0 {
+				resMap[alts[j]]++
+			} else {
+				resMap[alts[i]] += alpha
+				resMap[alts[j]] += alpha
+			}
+		}
+	}
+	return resMap
 }
@@ -0,0 +1,286 @@
+package comsoc
+
+import "sort"
+
+/*
+* Preference domains
+* Some structures of a profile of complete strict rankings guarantee good properties:
+* - single-peaked: the alternatives lie on an axis, and each voter prefers the alternatives closer
+*   to their peak on each side (there is then a Condorcet winner for an odd number of voters, the
+*   median peak). The axis is built from both ends, as the alternatives ranked last by some voter
+*   among the remaining ones are the ends of the remaining segment (Escoffier, Lang and Öztürk),
+*   backtracking when the rankings restricted to the alternatives placed so far are not single-peaked
+* - single-crossing: the voters can be ordered so that, for each pair of alternatives, the voters
+*   preferring the first one form a prefix or a suffix. The order is given by the distance to a
+*   voter at one end, which is the voter farthest from any voter
+* - group-separable: each subset of at least 2 alternatives can be split in two parts, each voter
+*   ranking one part entirely above the other. It suffices to split the alternatives recursively
+ */
+
+// Structures detected in a profile
+type Structure struct {
+	SinglePeaked   bool          `json:"single-peaked"`
+	Axis           []Alternative `json:"axis,omitempty"` // Axis on which the profile is single-peaked
+	SingleCrossing bool          `json:"single-crossing"`
+	VoterOrder     []int         `json:"voter-order,omitempty"` // Order of the voters (indices in the profile) for which the profile is single-crossing
+	GroupSeparable bool          `json:"group-separable"`
+}
+
+// Detects the structures of a profile of complete strict rankings
+func DetectStructure(p Profile) (Structure, error) {
+	var s Structure
+	var err error
+	s.Axis, err = SinglePeakedAxis(p)
+	if err != nil {
+		return s, err
+	}
+	s.SinglePeaked = s.Axis != nil
+	s.VoterOrder, _ = SingleCrossingOrder(p)
+	s.SingleCrossing = s.VoterOrder != nil
+	s.GroupSeparable, _ = IsGroupSeparable(p)
+	return s, nil
+}
+
+// Checks whether the profile is single-peaked on the axis
+func IsSinglePeaked(p Profile, axis []Alternative) bool {
+	for _, ranking := range p {
+		// the alternatives ranked so far form a segment of the axis containing the peak
+		left, right := rank(ranking[0], axis), rank(ranking[0], axis)
+		if left < 0 {
+			return false
+		}
+		for _, alt := range ranking[1:] {
+			r := rank(alt, axis)
+			if r < 0 {
+				return false
+			}
+			switch r {
+			case left - 1:
+				left--
+			case right + 1:
+				right++
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Axis on which the profile is single-peaked, or nil if there is none
+func SinglePeakedAxis(p Profile) ([]Alternative, error) {
+	err := checkProfile(p)
+	if err != nil {
+		return nil, err
+	}
+	m := len(p[0])
+	left := make([]Alternative, 0, m)  // left part of the axis, from the left end
+	right := make([]Alternative, 0, m) // right part of the axis, from the right end
+	remaining := make(map[Alternative]bool, m)
+	for _, alt := range p[0] {
+		remaining[alt] = true
+	}
+	// the profile restricted to the placed alternatives and any remaining one y should be single-peaked
+	// on the axis left, y, right (reversed), as the remaining alternatives form the middle segment
+	consistent := func() bool {
+		check := func(y Alternative) bool {
+			axis := append([]Alternative{}, left...)
+			if y != 0 {
+				axis = append(axis, y)
+			}
+			for i := len(right) - 1; i >= 0; i-- {
+				axis = append(axis, right[i])
+			}
+			for _, ranking := range p {
+				var restricted []Alternative
+				for _, alt := range ranking {
+					if alt == y || !remaining[alt] {
+						restricted = append(restricted, alt)
+					}
+				}
+				if !IsSinglePeaked(Profile{restricted}, axis) {
+					return false
+				}
+			}
+			return true
+		}
+		if len(remaining) == 0 {
+			return check(0)
+		}
+		for y := range remaining {
+			if !check(y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// places the remaining alternatives, backtracking when both sides are possible
+	var place func() bool
+	place = func() bool {
+		if len(remaining) == 0 {
+			return true
+		}
+		// alternatives ranked last among the remaining ones by some voter
+		var last []Alternative
+		for _, ranking := range p {
+			for i := m - 1; i >= 0; i-- {
+				if remaining[ranking[i]] {
+					if rank(ranking[i], last) < 0 {
+						last = append(last, ranking[i])
+					}
+					break
+				}
+			}
+		}
+		if len(last) > 2 {
+			return false
+		}
+		// each of them is placed on a side, and the other one (if any) on the other side
+		for i, x := range last {
+			if len(left) == 0 && len(right) == 0 && i > 0 {
+				break // the first alternative can be put on the left, by symmetry
+			}
+			var y Alternative
+			if len(last) == 2 {
+				y = last[1-i]
+			}
+			for _, xLeft := range []bool{true, false} {
+				l, r := x, y
+				if !xLeft {
+					l, r = y, x
+				}
+				nbLeft, nbRight := len(left), len(right)
+				for _, alt := range []Alternative{l, r} {
+					if alt != 0 {
+						delete(remaining, alt)
+					}
+				}
+				if l != 0 {
+					left = append(left, l)
+				}
+				if r != 0 {
+					right = append(right, r)
+				}
+				if consistent() && place() {
+					return true
+				}
+				left, right = left[:nbLeft], right[:nbRight]
+				remaining[x] = true
+				if y != 0 {
+					remaining[y] = true
+				}
+			}
+		}
+		return false
+	}
+	if !place() {
+		return nil, nil
+	}
+
+	axis := append([]Alternative{}, left...)
+	for i := len(right) - 1; i >= 0; i-- {
+		axis = append(axis, right[i])
+	}
+	return axis, nil
+}
+
+// Order of the voters (indices in the profile) for which the profile is single-crossing, or nil if there is none
+func SingleCrossingOrder(p Profile) ([]int, error) {
+	err := checkProfile(p)
+	if err != nil {
+		return nil, err
+	}
+	// the voter farthest from the first one is at an end of the order, if there is one
+	end := 0
+	distances := make([]int, len(p))
+	for v := range p {
+		distances[v] = kendallTau(p[0], p[v])
+		if distances[v] > distances[end] {
+			end = v
+		}
+	}
+	order := make([]int, len(p))
+	for v := range p {
+		distances[v] = kendallTau(p[end], p[v])
+		order[v] = v
+	}
+	sort.SliceStable(order, func(i, j int) bool { return distances[order[i]] < distances[order[j]] })
+
+	// each pair of alternatives changes of order at most once along the voters
+	alts := p[0]
+	for i, a := range alts {
+		for _, b := range alts[i+1:] {
+			var changes int
+			for k := 1; k < len(order); k++ {
+				if isPref(a, b, p[order[k]]) != isPref(a, b, p[order[k-1]]) {
+					changes++
+				}
+			}
+			if changes > 1 {
+				return nil, nil
+			}
+		}
+	}
+	return order, nil
+}
+
+// Number of pairs of alternatives on which two rankings disagree
+func kendallTau(r1 []Alternative, r2 []Alternative) int {
+	var res int
+	for i, a := range r1 {
+		for _, b := range r1[i+1:] {
+			if isPref(b, a, r2) {
+				res++
+			}
+		}
+	}
+	return res
+}
+
+// Checks whether the profile is group-separable
+func IsGroupSeparable(p Profile) (bool, error) {
+	err := checkProfile(p)
+	if err != nil {
+		return false, err
+	}
+	return separable(p, p[0]), nil
+}
+
+// Checks whether the alternatives alts can be split recursively, each voter ranking one part above the other.
+// Any split can be chosen, as the parts of a group-separable set are group-separable
+func separable(p Profile, alts []Alternative) bool {
+	if len(alts) < 2 {
+		return true
+	}
+	// restriction of the rankings to alts
+	restricted := make(Profile, len(p))
+	for v, ranking := range p {
+		for _, alt := range ranking {
+			if rank(alt, alts) >= 0 {
+				restricted[v] = append(restricted[v], alt)
+			}
+		}
+	}
+	// the part containing the best alternative of the first voter is a prefix of their ranking
+	for k := 1; k < len(alts); k++ {
+		part := restricted[0][:k]
+		split := true
+		for _, ranking := range restricted[1:] {
+			// part should be the k first or the k last alternatives of the ranking
+			prefix, suffix := true, true
+			for i := 0; i < k; i++ {
+				prefix = prefix && rank(ranking[i], part) >= 0
+				suffix = suffix && rank(ranking[len(ranking)-1-i], part) >= 0
+			}
+			if !prefix && !suffix {
+				split = false
+				break
+			}
+		}
+		if split {
+			return separable(p, part) && separable(p, restricted[0][k:])
+		}
+	}
+	return false
+}
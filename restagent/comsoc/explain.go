@@ -0,0 +1,33 @@
+package comsoc
+
+/*
+* Explanations of the results
+* Sequential rules record each of their rounds: the tallies (with the criterion they count),
+* the alternatives elected or eliminated at the end of the round, and whether the tie-break
+* had to be used to choose them. This trace lets voters check why an alternative lost
+ */
+
+// Trace of a round of a sequential rule
+type ExplanationRound struct {
+	Round        int                     `json:"round"`                // Round number (from 1)
+	Criterion    string                  `json:"criterion"`            // What the tallies count, e.g. "first places" or "borda score"
+	Tallies      map[Alternative]float64 `json:"tallies"`              // Tally of each remaining alternative
+	Elected      []Alternative           `json:"elected,omitempty"`    // Alternatives elected at the end of this round
+	Eliminated   []Alternative           `json:"eliminated,omitempty"` // Alternatives eliminated at the end of this round
+	TieBreakUsed bool                    `json:"tie-break-used"`       // True if the tie-break chose among alternatives with the same tally
+}
+
+// Rounds of a sequential rule, in order
+type Explanation []ExplanationRound
+
+// Adds a round to the explanation, with the next round number
+func (e *Explanation) addRound(criterion string, count Count, elected []Alternative, eliminated []Alternative, tieBreakUsed bool) {
+	*e = append(*e, ExplanationRound{
+		Round:        len(*e) + 1,
+		Criterion:    criterion,
+		Tallies:      ToFloatCount(count),
+		Elected:      elected,
+		Eliminated:   eliminated,
+		TieBreakUsed: tieBreakUsed,
+	})
+}
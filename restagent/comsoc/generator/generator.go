@@ -0,0 +1,229 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+/*
+* Synthetic preference profiles
+* A model draws the complete rankings of a number of voters on alternatives. All the draws come from the
+* random generator given, so that a profile can be drawn again from the same seed:
+* - impartial culture (IC): each voter draws a ranking uniformly
+* - impartial anonymous culture (IAC): each anonymous profile (multiset of rankings) is equally likely
+* - Mallows: the probability of a ranking decreases with its Kendall tau distance d to a central ranking,
+*   as phi^d (phi = 1 is IC, phi = 0 gives the central ranking to all the voters)
+* - Pólya-Eggenberger urn: the urn starts with one copy of each ranking, and alpha * m! copies of each drawn
+*   ranking are added (alpha = 0 is IC, alpha = 1/m! is IAC)
+* - single-peaked on the axis given by the order of the alternatives: uniformly among the single-peaked rankings
+*   (Walsh), or with a uniform peak, the next alternative being taken on either side with equal probability (Conitzer)
+* - Euclidean: the voters and the alternatives are drawn uniformly in [0, 1]^dimension, and each voter ranks
+*   the alternatives by increasing distance
+ */
+
+// Draws the rankings of nbVoters voters on alts
+type Generator func(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile
+
+// Names of the models
+const (
+	IC                   = "ic"
+	IAC                  = "iac"
+	Mallows              = "mallows" // parameter: phi in [0, 1]
+	Urn                  = "urn"     // parameter: alpha >= 0
+	SinglePeakedWalsh    = "sp-walsh"
+	SinglePeakedConitzer = "sp-conitzer"
+	Euclidean1D          = "euclidean-1d"
+	Euclidean2D          = "euclidean-2d"
+)
+
+var Models = []string{IC, IAC, Mallows, Urn, SinglePeakedWalsh, SinglePeakedConitzer, Euclidean1D, Euclidean2D}
+
+// Generator of a model, param being the parameter of Mallows and the urn (ignored otherwise)
+func New(model string, param float64) (Generator, error) {
+	switch model {
+	case IC:
+		return ImpartialCulture, nil
+	case IAC:
+		return ImpartialAnonymousCulture, nil
+	case Mallows:
+		if param < 0 || param > 1 {
+			return nil, fmt.Errorf("the dispersion phi of Mallows should be in [0, 1]")
+		}
+		return MallowsModel(nil, param), nil
+	case Urn:
+		if param < 0 {
+			return nil, fmt.Errorf("the parameter alpha of the urn should be positive")
+		}
+		return UrnModel(param), nil
+	case SinglePeakedWalsh:
+		return Walsh, nil
+	case SinglePeakedConitzer:
+		return Conitzer, nil
+	case Euclidean1D:
+		return Euclidean(1), nil
+	case Euclidean2D:
+		return Euclidean(2), nil
+	}
+	return nil, fmt.Errorf("unknown model %s", model)
+}
+
+// Ranking of alts drawn uniformly
+func Ranking(rng *rand.Rand, alts []comsoc.Alternative) []comsoc.Alternative {
+	res := make([]comsoc.Alternative, len(alts))
+	for i, j := range rng.Perm(len(alts)) {
+		res[i] = alts[j]
+	}
+	return res
+}
+
+// Impartial culture
+func ImpartialCulture(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+	p := make(comsoc.Profile, nbVoters)
+	for i := range p {
+		p[i] = Ranking(rng, alts)
+	}
+	return p
+}
+
+// Impartial anonymous culture, drawn as the urn with alpha = 1/m!
+func ImpartialAnonymousCulture(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+	var factorial = 1.0
+	for i := 2; i <= len(alts); i++ {
+		factorial *= float64(i)
+	}
+	return UrnModel(1/factorial)(rng, nbVoters, alts)
+}
+
+// Mallows model with dispersion phi around center (drawn uniformly for each profile if nil), by repeated insertion:
+// the i-th alternative of center is inserted at the position j <= i with a probability proportional to phi^(i-j)
+func MallowsModel(center []comsoc.Alternative, phi float64) Generator {
+	return func(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+		c := center
+		if c == nil {
+			c = Ranking(rng, alts)
+		}
+		p := make(comsoc.Profile, nbVoters)
+		for v := range p {
+			ranking := make([]comsoc.Alternative, 0, len(c))
+			for i, alt := range c {
+				// weights phi^(i-j) for j = 0..i
+				var total float64
+				weights := make([]float64, i+1)
+				for j := range weights {
+					weights[j] = math.Pow(phi, float64(i-j))
+					total += weights[j]
+				}
+				x := rng.Float64() * total
+				j := 0
+				for ; j < i && x >= weights[j]; j++ {
+					x -= weights[j]
+				}
+				ranking = append(ranking, 0)
+				copy(ranking[j+1:], ranking[j:])
+				ranking[j] = alt
+			}
+			p[v] = ranking
+		}
+		return p
+	}
+}
+
+// Pólya-Eggenberger urn: the i-th voter draws a new ranking uniformly with probability 1 / (1 + i * alpha),
+// and copies the ranking of one of the previous voters otherwise
+func UrnModel(alpha float64) Generator {
+	return func(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+		p := make(comsoc.Profile, nbVoters)
+		for i := range p {
+			if rng.Float64()*(1+float64(i)*alpha) < 1 {
+				p[i] = Ranking(rng, alts)
+			} else {
+				p[i] = p[rng.Intn(i)]
+			}
+		}
+		return p
+	}
+}
+
+// Single-peaked rankings on the axis alts drawn uniformly (Walsh): the ranking is built from the last
+// alternative, which is either end of the remaining interval of the axis with equal probability
+func Walsh(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+	p := make(comsoc.Profile, nbVoters)
+	for v := range p {
+		ranking := make([]comsoc.Alternative, len(alts))
+		left, right := 0, len(alts)-1
+		for pos := len(alts) - 1; pos >= 0; pos-- {
+			if left == right || rng.Intn(2) == 0 {
+				ranking[pos] = alts[right]
+				right--
+			} else {
+				ranking[pos] = alts[left]
+				left++
+			}
+		}
+		p[v] = ranking
+	}
+	return p
+}
+
+// Single-peaked rankings on the axis alts (Conitzer): the peak is drawn uniformly, then the next alternative
+// is the closest one on either side of the interval ranked so far, with equal probability
+func Conitzer(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+	p := make(comsoc.Profile, nbVoters)
+	for v := range p {
+		peak := rng.Intn(len(alts))
+		ranking := []comsoc.Alternative{alts[peak]}
+		left, right := peak-1, peak+1
+		for len(ranking) < len(alts) {
+			if right == len(alts) || (left >= 0 && rng.Intn(2) == 0) {
+				ranking = append(ranking, alts[left])
+				left--
+			} else {
+				ranking = append(ranking, alts[right])
+				right++
+			}
+		}
+		p[v] = ranking
+	}
+	return p
+}
+
+// Euclidean model in [0, 1]^dimension
+func Euclidean(dimension int) Generator {
+	return func(rng *rand.Rand, nbVoters int, alts []comsoc.Alternative) comsoc.Profile {
+		point := func() []float64 {
+			res := make([]float64, dimension)
+			for i := range res {
+				res[i] = rng.Float64()
+			}
+			return res
+		}
+		positions := make([][]float64, len(alts))
+		for i := range positions {
+			positions[i] = point()
+		}
+		p := make(comsoc.Profile, nbVoters)
+		for v := range p {
+			voter := point()
+			distances := make([]float64, len(alts))
+			for i, pos := range positions {
+				for d := range pos {
+					distances[i] += (pos[d] - voter[d]) * (pos[d] - voter[d])
+				}
+			}
+			order := make([]int, len(alts))
+			for i := range order {
+				order[i] = i
+			}
+			sort.SliceStable(order, func(i, j int) bool { return distances[order[i]] < distances[order[j]] })
+			p[v] = make([]comsoc.Alternative, len(alts))
+			for i, j := range order {
+				p[v][i] = alts[j]
+			}
+		}
+		return p
+	}
+}
@@ -0,0 +1,175 @@
+package comsoc
+
+/*
+* Kemeny-Young Method
+* The resulting ranking is the one minimizing the total Kendall-tau distance to the profile,
+* i.e. the total number of pairs (voter, duel) on which the ranking disagrees with the voter
+* Finding it is NP-hard: it is solved exactly by dynamic programming over the subsets of
+* alternatives up to KemenyExactLimit alternatives, and by a local search beyond
+ */
+
+// Maximum number of alternatives for which the exact solver is used
+const KemenyExactLimit = 12
+
+// Returns the alternatives ordered by the tie-break (alternatives absent from the tie-break come last)
+func tieBreakOrder(alts []Alternative, tieBreak []Alternative) []int {
+	order := make([]int, 0, len(alts))
+	used := make([]bool, len(alts))
+	for _, tb := range tieBreak {
+		for i, alt := range alts {
+			if alt == tb && !used[i] {
+				order = append(order, i)
+				used[i] = true
+			}
+		}
+	}
+	for i := range alts {
+		if !used[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// Exact solver: cost[S] is the minimal number of disagreements for ranking the subset S
+// (disagreements with alternatives outside of S do not depend on the order of S)
+func kemenyExact(duels [][]int, order []int) []int {
+	m := len(duels)
+	cost := make([]int, 1<<uint(m))
+	for mask := 1; mask < len(cost); mask++ {
+		cost[mask] = -1
+		for i := 0; i < m; i++ {
+			if mask&(1<<uint(i)) == 0 {
+				continue
+			}
+			// Cost of putting i on top of the other alternatives of mask
+			c := cost[mask^(1<<uint(i))]
+			for j := 0; j < m; j++ {
+				if j != i && mask&(1<<uint(j)) != 0 {
+					c += duels[j][i]
+				}
+			}
+			if cost[mask] < 0 || c < cost[mask] {
+				cost[mask] = c
+			}
+		}
+	}
+	// Rebuild the ranking from the top, choosing among the optimal alternatives with the tie-break
+	res := make([]int, 0, m)
+	mask := len(cost) - 1
+	for mask != 0 {
+		for _, i := range order {
+			if mask&(1<<uint(i)) == 0 {
+				continue
+			}
+			c := cost[mask^(1<<uint(i))]
+			for j := 0; j < m; j++ {
+				if j != i && mask&(1<<uint(j)) != 0 {
+					c += duels[j][i]
+				}
+			}
+			if c == cost[mask] {
+				res = append(res, i)
+				mask ^= 1 << uint(i)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Heuristic solver: starts from the ranking by number of won duels (ties broken by the tie-break)
+// and moves one alternative at a time as long as it decreases the distance
+func kemenyLocalSearch(duels [][]int, order []int) []int {
+	m := len(duels)
+	score := make([]int, m)
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			score[i] += duels[i][j]
+		}
+	}
+	// Insertion sort by score, stable with respect to the tie-break order
+	res := make([]int, 0, m)
+	for _, i := range order {
+		pos := len(res)
+		for pos > 0 && score[res[pos-1]] < score[i] {
+			pos--
+		}
+		res = append(res, 0)
+		copy(res[pos+1:], res[pos:])
+		res[pos] = i
+	}
+
+	for improved := true; improved; {
+		improved = false
+		bestDelta, bestFrom, bestTo := 0, -1, -1
+		for from := 0; from < m; from++ {
+			x := res[from]
+			// Moving x down, below res[to]
+			delta := 0
+			for to := from + 1; to < m; to++ {
+				y := res[to]
+				delta += duels[x][y] - duels[y][x]
+				if delta < bestDelta {
+					bestDelta, bestFrom, bestTo = delta, from, to
+				}
+			}
+			// Moving x up, above res[to]
+			delta = 0
+			for to := from - 1; to >= 0; to-- {
+				y := res[to]
+				delta += duels[y][x] - duels[x][y]
+				if delta < bestDelta {
+					bestDelta, bestFrom, bestTo = delta, from, to
+				}
+			}
+		}
+		if bestFrom >= 0 {
+			x := res[bestFrom]
+			if bestFrom < bestTo {
+				copy(res[bestFrom:bestTo], res[bestFrom+1:bestTo+1])
+			} else {
+				copy(res[bestTo+1:bestFrom+1], res[bestTo:bestFrom])
+			}
+			res[bestTo] = x
+			improved = true
+		}
+	}
+	return res
+}
+
+// Returns the Kemeny ranking, and whether it has been computed by the exact solver.
+// The tie-break is used to choose among equally optimal rankings
+func KemenySWF(p Profile, tieBreak []Alternative) (ranking []Alternative, exact bool, err error) {
+	return KemenyWeightedSWF(Compress(p), tieBreak)
+}
+
+func KemenyWeightedSWF(wp WeightedProfile, tieBreak []Alternative) (ranking []Alternative, exact bool, err error) {
+	pm, err := NewPairwiseMatrix(wp)
+	if err != nil {
+		return nil, false, err
+	}
+	ranking, exact = kemeny(pm, tieBreak)
+	return ranking, exact, nil
+}
+
+// Kemeny ranking computed from the pairwise matrix
+func kemeny(pm *PairwiseMatrix, tieBreak []Alternative) (ranking []Alternative, exact bool) {
+	alts, duels := pm.Alts, pm.Duels
+	m := len(alts)
+
+	order := tieBreakOrder(alts, tieBreak)
+	var indexes []int
+	exact = m <= KemenyExactLimit
+	if exact {
+		indexes = kemenyExact(duels, order)
+	} else {
+		indexes = kemenyLocalSearch(duels, order)
+	}
+
+	ranking = make([]Alternative, m)
+	for i, ind := range indexes {
+		ranking[i] = alts[ind]
+	}
+	return ranking, exact
+}
@@ -2,29 +2,33 @@ package comsoc
 
 // Simple Majority Method
 func MajoritySWF(p Profile) (count Count, err error) {
-	err = checkProfile(p)
+	return MajorityWeightedSWF(Compress(p))
+}
+
+func MajorityWeightedSWF(wp WeightedProfile) (count Count, err error) {
+	err = checkWeightedProfile(wp)
 	if err != nil {
 		return nil, err
 	}
-	count = make(Count, len(p[0])) // Initialize the map
-	for _, alt := range p[0] {
+	alts := wp.Alternatives()
+	count = make(Count, len(alts)) // Initialize the map
+	for _, alt := range alts {
 		// Initialize to 0
 		count[alt] = 0
 	}
 	// Counting votes from the profile
-	for _, votant := range p {
-		_, ok := count[votant[0]] // votant[0] is the favorite of votant
-		if ok {
-			count[votant[0]]++
-		} else {
-			count[votant[0]] = 1
-		}
+	for r, votant := range wp.Rankings {
+		count[votant[0]] += wp.Weights[r] // votant[0] is the favorite of votant
 	}
 	return count, nil
 }
 
 func MajoritySCF(p Profile) (bestAlts []Alternative, err error) {
-	count, err := MajoritySWF(p)
+	return MajorityWeightedSCF(Compress(p))
+}
+
+func MajorityWeightedSCF(wp WeightedProfile) (bestAlts []Alternative, err error) {
+	count, err := MajorityWeightedSWF(wp)
 	if err != nil {
 		return nil, err
 	}
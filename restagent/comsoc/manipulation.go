@@ -0,0 +1,274 @@
+package comsoc
+
+import "errors"
+
+/*
+* Manipulability
+* A coalition of voters manipulates a rule when, by reporting other ballots than their sincere
+* rankings, they obtain a winner that all of them prefer to the sincere winner. The coalitions
+* are tried by increasing size, so that a single voter is found first if they can manipulate.
+* The rules being anonymous, the voters giving the same ranking are interchangeable: a coalition
+* is given by the number of its members giving each ranking, and the ballots of the coalition
+* by a multiset of rankings.
+* When the coalition has too many possible ballots (more than ManipulationLimit), only the
+* ballots ranking the target first and the sincere winner last are tried (compromising and
+* burying), and the search is no longer exhaustive: a profile may be manipulable without any
+* manipulation being found. The whole search stops after ManipulationSearchLimit computations
+* of the winner, and is then not exhaustive either
+ */
+
+// Above this number of combinations of ballots for a coalition, only compromising and burying are tried
+const ManipulationLimit = 50000
+
+// Above this number of computations of the winner, the search of a manipulation stops
+const ManipulationSearchLimit = 100000
+
+// Largest coalition size that should be searched (the number of coalitions grows quickly with it)
+const MaxCoalitionSize = 5
+
+// Ballots reported by a coalition to change the winner
+type Manipulation struct {
+	Voters        []int           `json:"voters"`         // Indices of the manipulating voters in the profile
+	Ballots       [][]Alternative `json:"ballots"`        // Ballots reported instead of their sincere rankings (in the order of Voters)
+	SincereWinner Alternative     `json:"sincere-winner"` // Winner when all the voters are sincere
+	Winner        Alternative     `json:"winner"`         // Winner obtained, which all the manipulators prefer to SincereWinner
+}
+
+// Winner of a rule on complete rankings of alts, with the tie-break
+func RuleWinner(rule Rule, options RuleOptions, alts []Alternative, tieBreak []Alternative) func(Profile) (Alternative, error) {
+	return func(p Profile) (Alternative, error) {
+		res, err := rule.Compute(Votes{Alts: alts, Profile: p}, options, tieBreak)
+		return res.Winner, err
+	}
+}
+
+// Searches a coalition of at most k voters which can manipulate the rule giving the winner of a profile
+// (e.g. RuleWinner or the result of SCFFactory). Returns nil if there is none, and whether the search was exhaustive.
+// Outcomes without a winner (0) are not compared, so they are neither manipulated nor obtained by a manipulation
+func FindManipulation(p Profile, winner func(Profile) (Alternative, error), k int) (*Manipulation, bool, error) {
+	err := checkProfile(p)
+	if err != nil {
+		return nil, false, err
+	}
+	if k < 1 {
+		return nil, false, errors.New("the coalition should have at least 1 voter")
+	}
+	sincere, err := winner(p)
+	if err != nil || sincere == 0 {
+		return nil, true, err
+	}
+
+	groups := groupVoters(p)
+	// the ballots are only enumerated if there are not too many rankings
+	var rankings [][]Alternative
+	if nbPermutations(len(p[0]), ManipulationLimit) <= ManipulationLimit {
+		rankings = allRankings(p[0])
+	}
+
+	var exhaustive = true
+	var budget = ManipulationSearchLimit
+	for size := 1; size <= k && size <= len(p); size++ {
+		var found *Manipulation
+		_, err := forEachCoalition(groups, size, func(voters []int) (bool, error) {
+			m, ex, err := manipulateWith(p, winner, sincere, voters, rankings, &budget)
+			found = m
+			exhaustive = exhaustive && ex
+			return found != nil || budget <= 0, err
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if found != nil {
+			return found, exhaustive, nil
+		}
+		if budget <= 0 {
+			return nil, false, nil
+		}
+	}
+	return nil, exhaustive, nil
+}
+
+// Indices of the voters of p, grouped by ranking
+func groupVoters(p Profile) [][]int {
+	var groups [][]int
+	index := make(map[string]int)
+	for i, ranking := range p {
+		key := rankingKey(ranking)
+		g, found := index[key]
+		if !found {
+			g = len(groups)
+			index[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// Calls f on each coalition of size voters, up to the order of the voters of a group, until f returns true
+// (which is then returned) or an error
+func forEachCoalition(groups [][]int, size int, f func(voters []int) (bool, error)) (bool, error) {
+	counts := make([]int, len(groups)) // number of members of the coalition in each group
+	var search func(g int, left int) (bool, error)
+	search = func(g int, left int) (bool, error) {
+		if left == 0 {
+			var voters []int
+			for i, c := range counts[:g] {
+				voters = append(voters, groups[i][:c]...)
+			}
+			return f(voters)
+		}
+		if g == len(groups) {
+			return false, nil
+		}
+		for c := len(groups[g]); c >= 0; c-- {
+			if c > left {
+				continue
+			}
+			counts[g] = c
+			stop, err := search(g+1, left-c)
+			if stop || err != nil {
+				counts[g] = 0
+				return stop, err
+			}
+		}
+		counts[g] = 0
+		return false, nil
+	}
+	return search(0, size)
+}
+
+// Searches ballots for the voters so that all of them prefer the winner to the sincere one,
+// computing the winner at most budget times (budget is decreased)
+func manipulateWith(p Profile, winner func(Profile) (Alternative, error), sincere Alternative, voters []int, rankings [][]Alternative, budget *int) (*Manipulation, bool, error) {
+	// alternatives that all the voters prefer to the sincere winner
+	var targets []Alternative
+	for _, alt := range p[0] {
+		var all = alt != sincere
+		for _, v := range voters {
+			if !isPref(alt, sincere, p[v]) {
+				all = false
+				break
+			}
+		}
+		if all {
+			targets = append(targets, alt)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, true, nil
+	}
+
+	modified := append(Profile{}, p...)
+	try := func(ballots [][]Alternative) (*Manipulation, error) {
+		for i, v := range voters {
+			modified[v] = ballots[i]
+		}
+		*budget--
+		w, err := winner(modified)
+		if err != nil {
+			return nil, err
+		}
+		if w != 0 && rank(w, targets) >= 0 {
+			return &Manipulation{Voters: voters, Ballots: ballots, SincereWinner: sincere, Winner: w}, nil
+		}
+		return nil, nil
+	}
+
+	if rankings == nil || nbMultisets(len(rankings), len(voters), ManipulationLimit) > ManipulationLimit {
+		// compromising and burying: each voter ranks the target first and the sincere winner last
+		for _, target := range targets {
+			ballots := make([][]Alternative, len(voters))
+			for i, v := range voters {
+				ballots[i] = []Alternative{target}
+				for _, alt := range p[v] {
+					if alt != target && alt != sincere {
+						ballots[i] = append(ballots[i], alt)
+					}
+				}
+				ballots[i] = append(ballots[i], sincere)
+			}
+			if *budget <= 0 {
+				return nil, false, nil
+			}
+			m, err := try(ballots)
+			if m != nil || err != nil {
+				return m, false, err
+			}
+		}
+		return nil, false, nil
+	}
+
+	// multisets of ballots, given by non-decreasing indices in rankings
+	idx := make([]int, len(voters))
+	for ok := true; ok; ok = nextIndices(idx, len(rankings)) {
+		if *budget <= 0 {
+			return nil, false, nil
+		}
+		ballots := make([][]Alternative, len(voters))
+		for i, r := range idx {
+			ballots[i] = rankings[r]
+		}
+		m, err := try(ballots)
+		if m != nil || err != nil {
+			return m, true, err
+		}
+	}
+	return nil, true, nil
+}
+
+// All the rankings of the alternatives of ranking
+func allRankings(ranking []Alternative) [][]Alternative {
+	if len(ranking) == 0 {
+		return [][]Alternative{{}}
+	}
+	var res [][]Alternative
+	for _, sub := range allRankings(ranking[1:]) {
+		for pos := 0; pos <= len(sub); pos++ {
+			r := make([]Alternative, 0, len(ranking))
+			r = append(r, sub[:pos]...)
+			r = append(r, ranking[0])
+			r = append(r, sub[pos:]...)
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Next non-decreasing sequence of indices in [0, k). Returns false after the last one
+func nextIndices(idx []int, k int) bool {
+	for i := len(idx) - 1; i >= 0; i-- {
+		if idx[i] < k-1 {
+			idx[i]++
+			for j := i + 1; j < len(idx); j++ {
+				idx[j] = idx[i]
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Number of multisets of n elements among k, or a number above limit if it is larger
+func nbMultisets(k int, n int, limit int) int {
+	var res = 1
+	for i := 1; i <= n; i++ {
+		res = res * (k + i - 1) / i
+		if res > limit {
+			return limit + 1
+		}
+	}
+	return res
+}
+
+// Number of rankings of m alternatives, i.e. m!, or a number above limit if it is larger
+func nbPermutations(m int, limit int) int {
+	var res = 1
+	for i := 2; i <= m; i++ {
+		res *= i
+		if res > limit {
+			return limit + 1
+		}
+	}
+	return res
+}
@@ -0,0 +1,281 @@
+package comsoc
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+/*
+* Margin of victory
+* The margin of victory is the smallest number of ballots which must be changed to change the
+* winner (the tie-break being applied). A small margin flags a close election, e.g. for a recount.
+* - For positional rules and approval, it is computed exactly: for each challenger c, the voters
+*   giving the most points to the winner w relative to c change their ballots to rank c first and
+*   w last, which is the best change for c, until c beats w
+* - For the other rules, it is bounded. The upper bound is given by changes which were checked
+*   to change the winner (some voters ranking c first and w last). The lower bound is 1, or for
+*   Condorcet-consistent rules half of the smallest pairwise margin of the Condorcet winner, as
+*   each changed ballot decreases a pairwise margin by at most 2. The ballots changes of the sizes
+*   between the bounds are then enumerated, as long as there are at most MarginSearchLimit of them
+ */
+
+// Above this number of computations of the winner, the search of smaller ballot changes stops
+const MarginSearchLimit = 5000
+
+// Bounds of the margin of victory (equal if it is exact)
+type Margin struct {
+	Lower int  `json:"lower"`           // Changing fewer ballots cannot change the winner
+	Upper int  `json:"upper,omitempty"` // Changing this number of ballots can change the winner (0 if no such change was found)
+	Exact bool `json:"exact"`           // True if Lower == Upper
+}
+
+func newMargin(lower int, upper int) Margin {
+	return Margin{Lower: lower, Upper: upper, Exact: lower == upper}
+}
+
+// Exact margin of victory of the positional scoring rule given by scoreVector, the ties being broken by tieBreak
+func PositionalMargin(wp WeightedProfile, scoreVector []float64, tieBreak []Alternative) (Margin, error) {
+	count, err := PositionalWeightedSWF(scoreVector)(wp)
+	if err != nil {
+		return Margin{}, err
+	}
+	last := len(scoreVector) - 1
+	return scoreMargin(count, wp.Weights, tieBreak, func(r int, c Alternative, w Alternative) float64 {
+		// the ballot ranking c first and w last gives the largest difference of points between them
+		return (scoreVector[0] - scoreVector[last]) - (scoreVector[rank(c, wp.Rankings[r])] - scoreVector[rank(w, wp.Rankings[r])])
+	})
+}
+
+// Exact margin of victory of approval, the ties being broken by tieBreak
+func ApprovalMargin(p Profile, thresholds []int, tieBreak []Alternative) (Margin, error) {
+	wp, wthresholds, err := CompressApproval(p, thresholds)
+	if err != nil {
+		return Margin{}, err
+	}
+	count, err := ApprovalWeightedSWF(wp, wthresholds)
+	if err != nil {
+		return Margin{}, err
+	}
+	approved := func(alt Alternative, r int) float64 {
+		if rank(alt, wp.Rankings[r]) < wthresholds[r] {
+			return 1
+		}
+		return 0
+	}
+	return scoreMargin(ToFloatCount(count), wp.Weights, tieBreak, func(r int, c Alternative, w Alternative) float64 {
+		// the ballot approving only c gives the largest difference of points between them
+		return 1 - (approved(c, r) - approved(w, r))
+	})
+}
+
+// Exact margin of victory of a rule ranking the alternatives by score, gain(r, c, w) being the increase of
+// score(c) - score(w) when a voter of the ranking r (of weight weights[r]) changes their ballot
+func scoreMargin(count FloatCount, weights []int, tieBreak []Alternative, gain func(r int, c Alternative, w Alternative) float64) (Margin, error) {
+	ranking, err := RankFloatCount(count, TieBreakFactory(tieBreak))
+	if err != nil {
+		return Margin{}, err
+	}
+	w := ranking[0]
+	const eps = 1e-9
+	var best = -1
+	for _, c := range ranking[1:] {
+		// c beats w if its score is higher, or equal and c comes first in the tie-break
+		cFirst := rank(c, tieBreak) < rank(w, tieBreak)
+		beats := func(diff float64) bool {
+			if cFirst {
+				return diff > -eps
+			}
+			return diff > eps
+		}
+		gains := make([]float64, len(weights))
+		order := make([]int, len(weights))
+		for r := range weights {
+			gains[r] = gain(r, c, w)
+			order[r] = r
+		}
+		sort.SliceStable(order, func(i, j int) bool { return gains[order[i]] > gains[order[j]] })
+
+		diff := count[c] - count[w]
+		var nb int
+		for _, r := range order {
+			if beats(diff) || gains[r] <= eps {
+				break
+			}
+			// number of voters of the ranking r needed, at most its weight
+			t := int(math.Ceil(-diff/gains[r] - eps))
+			if t < 1 {
+				t = 1
+			}
+			for t > 1 && beats(diff+float64(t-1)*gains[r]) {
+				t--
+			}
+			for !beats(diff+float64(t)*gains[r]) && t < weights[r] {
+				t++
+			}
+			if t > weights[r] {
+				t = weights[r]
+			}
+			diff += float64(t) * gains[r]
+			nb += t
+		}
+		if beats(diff) && (best < 0 || nb < best) {
+			best = nb
+		}
+	}
+	if best < 0 {
+		return Margin{}, errors.New("no change of the ballots can change the winner")
+	}
+	return newMargin(best, best), nil
+}
+
+// Bounds of the margin of victory of any rule on complete rankings giving the winner of a profile
+// (e.g. RuleWinner). If condorcetConsistent, the rule is known to elect the Condorcet winner when there is one
+func MarginBounds(p Profile, winner func(Profile) (Alternative, error), condorcetConsistent bool) (Margin, error) {
+	err := checkProfile(p)
+	if err != nil {
+		return Margin{}, err
+	}
+	w, err := winner(p)
+	if err != nil {
+		return Margin{}, err
+	}
+	if w == 0 {
+		return Margin{}, errors.New("there is no winner")
+	}
+	changed := func(modified Profile) (bool, error) {
+		res, err := winner(modified)
+		return res != w, err
+	}
+
+	// Lower bound
+	var lower = 1
+	if condorcetConsistent {
+		pm, err := NewPairwiseMatrix(Compress(p))
+		if err != nil {
+			return Margin{}, err
+		}
+		// smallest margin of w against another alternative, which is positive if w is the Condorcet winner
+		var minMargin = len(p)
+		for _, c := range p[0] {
+			if c != w && pm.Margin(w, c) < minMargin {
+				minMargin = pm.Margin(w, c)
+			}
+		}
+		if minMargin > 0 {
+			lower = (minMargin + 1) / 2
+		}
+	}
+
+	// Upper bound: for each challenger c, the voters ranking w highest relative to c rank c first and w last
+	var upper = 0
+	for _, c := range p[0] {
+		if c == w {
+			continue
+		}
+		order := make([]int, len(p))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return rank(c, p[order[i]])-rank(w, p[order[i]]) > rank(c, p[order[j]])-rank(w, p[order[j]])
+		})
+		// the first k voters of order change their ballots
+		changeFirst := func(k int) (bool, error) {
+			modified := append(Profile{}, p...)
+			for _, v := range order[:k] {
+				modified[v] = []Alternative{c}
+				for _, alt := range p[v] {
+					if alt != c && alt != w {
+						modified[v] = append(modified[v], alt)
+					}
+				}
+				modified[v] = append(modified[v], w)
+			}
+			return changed(modified)
+		}
+		max := len(p)
+		if upper > 0 {
+			max = upper - 1
+		}
+		// doubling, then binary search between the last failure and the first success
+		var failed, succeeded = 0, 0
+		for k := 1; succeeded == 0 && failed < max; {
+			ok, err := changeFirst(k)
+			if err != nil {
+				return Margin{}, err
+			}
+			if ok {
+				succeeded = k
+			} else {
+				failed = k
+				k *= 2
+				if k > max {
+					k = max
+				}
+			}
+		}
+		if succeeded == 0 {
+			continue
+		}
+		for failed+1 < succeeded {
+			mid := (failed + succeeded) / 2
+			ok, err := changeFirst(mid)
+			if err != nil {
+				return Margin{}, err
+			}
+			if ok {
+				succeeded = mid
+			} else {
+				failed = mid
+			}
+		}
+		upper = succeeded
+	}
+	if upper > 0 && lower > upper {
+		lower = upper // only if the rule is not Condorcet-consistent after all
+	}
+
+	// Exhaustive search of smaller changes, by increasing number of changed ballots
+	// (the rankings are only built if they are few enough to be tried)
+	if nbPermutations(len(p[0]), MarginSearchLimit) > MarginSearchLimit {
+		return newMargin(lower, upper), nil
+	}
+	rankings := allRankings(p[0])
+	groups := groupVoters(p)
+	var budget = MarginSearchLimit
+	for k := lower; upper == 0 || k < upper; k++ {
+		if k > len(p) || nbMultisets(len(rankings), k, budget) > budget {
+			break
+		}
+		found, err := forEachCoalition(groups, k, func(voters []int) (bool, error) {
+			modified := append(Profile{}, p...)
+			idx := make([]int, len(voters))
+			for ok := true; ok; ok = nextIndices(idx, len(rankings)) {
+				budget--
+				if budget < 0 {
+					return true, nil
+				}
+				for i, v := range voters {
+					modified[v] = rankings[idx[i]]
+				}
+				ok, err := changed(modified)
+				if ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		})
+		if err != nil {
+			return Margin{}, err
+		}
+		if budget < 0 {
+			break
+		}
+		if found {
+			return newMargin(k, k), nil
+		}
+		lower = k + 1
+	}
+	return newMargin(lower, upper), nil
+}
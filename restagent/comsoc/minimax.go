@@ -0,0 +1,89 @@
+package comsoc
+
+import "fmt"
+
+/*
+* Minimax Method (Simpson-Kramer)
+* Each candidate is evaluated by its worst pairwise defeat, and
+* the elected candidate is the one whose worst defeat is the smallest
+* The strength of a defeat of a against b depends on the variant:
+* - winning votes: number of voters preferring b to a if b wins the duel, 0 otherwise
+* - margins: number of voters preferring b to a minus number of voters preferring a to b
+* - pairwise opposition: number of voters preferring b to a, whoever wins the duel
+ */
+
+const MinimaxWinningVotes = "winning-votes"
+const MinimaxMargins = "margins"
+const MinimaxPairwiseOpposition = "pairwise-opposition"
+
+var MinimaxVariants = []string{MinimaxWinningVotes, MinimaxMargins, MinimaxPairwiseOpposition}
+
+// Strength of the defeat of alt1 against alt2 according to the variant
+func minimaxDefeat(pm *PairwiseMatrix, alt1 Alternative, alt2 Alternative, variant string) (int, error) {
+	against, support := pm.Duel(alt2, alt1), pm.Duel(alt1, alt2)
+	switch variant {
+	case MinimaxWinningVotes:
+		if against > support {
+			return against, nil
+		}
+		return 0, nil
+	case MinimaxMargins:
+		return against - support, nil
+	case MinimaxPairwiseOpposition:
+		return against, nil
+	}
+	return 0, fmt.Errorf("unknown minimax variant %s", variant)
+}
+
+// The score of a candidate is the number of voters minus its worst defeat, so that
+// the best candidate has the highest score and all the scores are positive
+func MinimaxSWF(p Profile, variant string) (Count, error) {
+	return MinimaxWeightedSWF(Compress(p), variant)
+}
+
+func MinimaxWeightedSWF(wp WeightedProfile, variant string) (Count, error) {
+	pm, err := NewPairwiseMatrix(wp)
+	if err != nil {
+		return nil, err
+	}
+	return minimaxScores(pm, variant)
+}
+
+// Minimax scores computed from the pairwise matrix
+func minimaxScores(pm *PairwiseMatrix, variant string) (Count, error) {
+	alts, nbVoters := pm.Alts, pm.NbVoters
+	count := make(Count, len(alts))
+	for _, a := range alts {
+		worst := -nbVoters
+		for _, b := range alts {
+			if a == b {
+				continue
+			}
+			defeat, err := minimaxDefeat(pm, a, b, variant)
+			if err != nil {
+				return nil, err
+			}
+			if defeat > worst {
+				worst = defeat
+			}
+		}
+		count[a] = nbVoters - worst
+	}
+	return count, nil
+}
+
+func MinimaxSCF(p Profile, variant string) (bestAlts []Alternative, err error) {
+	count, err := MinimaxSWF(p, variant)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
+
+func MinimaxWeightedSCF(wp WeightedProfile, variant string) (bestAlts []Alternative, err error) {
+	count, err := MinimaxWeightedSWF(wp, variant)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
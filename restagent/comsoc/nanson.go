@@ -0,0 +1,72 @@
+package comsoc
+
+import "sort"
+
+/*
+* Nanson Method
+* In each round, the Borda scores are computed on the remaining candidates
+* and all the candidates whose score is strictly below the average are eliminated
+* If all the remaining candidates have the same score, the tie-break eliminates one of them
+* Candidates eliminated in the same round are ordered by score, then by the tie-break
+ */
+
+func NansonSWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error) {
+	return NansonWeightedSWF_TieBreak(Compress(p), tieBreak)
+}
+
+func NansonWeightedSWF_TieBreak(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, error) {
+	ranking, _, err := NansonWeightedExplain(wp, tieBreak)
+	return ranking, err
+}
+
+// Same as NansonSWF_TieBreak, with the trace of the rounds
+func NansonExplain(p Profile, tieBreak []Alternative) ([]Alternative, Explanation, error) {
+	return NansonWeightedExplain(Compress(p), tieBreak)
+}
+
+func NansonWeightedExplain(wp WeightedProfile, tieBreak []Alternative) (ranking []Alternative, explanation Explanation, err error) {
+	err = checkWeightedProfile(wp)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining := wp // removeAlternatives returns a new profile, so wp is not modified
+	eliminated := make([]Alternative, 0, len(wp.Alternatives()))
+	for len(remaining.Alternatives()) > 1 {
+		count, err := BordaWeightedSWF(remaining)
+		if err != nil {
+			return nil, nil, err
+		}
+		var total int
+		for _, score := range count {
+			total += score
+		}
+		// score < total/len(count), without rounding
+		belowAvg := make([]Alternative, 0)
+		for alt, score := range count {
+			if score*len(count) < total {
+				belowAvg = append(belowAvg, alt)
+			}
+		}
+		var tieBreakUsed = false
+		if len(belowAvg) == 0 {
+			belowAvg = []Alternative{worstByTieBreak(remaining.Alternatives(), tieBreak)}
+			tieBreakUsed = true
+		}
+		// The worst candidates are eliminated first, the tie-break ordering those with equal scores
+		sort.Slice(belowAvg, func(i, j int) bool {
+			if count[belowAvg[i]] != count[belowAvg[j]] {
+				return count[belowAvg[i]] < count[belowAvg[j]]
+			}
+			return rank(belowAvg[i], tieBreak) > rank(belowAvg[j], tieBreak)
+		})
+		for i := 1; i < len(belowAvg); i++ {
+			if count[belowAvg[i]] == count[belowAvg[i-1]] {
+				tieBreakUsed = true
+			}
+		}
+		explanation.addRound("borda score", count, nil, belowAvg, tieBreakUsed)
+		eliminated = append(eliminated, belowAvg...)
+		remaining = removeAlternatives(remaining, belowAvg...)
+	}
+	return eliminationRanking(remaining.Alternatives(), eliminated), explanation, nil
+}
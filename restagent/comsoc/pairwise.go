@@ -0,0 +1,167 @@
+package comsoc
+
+import "sync"
+
+/*
+* Pairwise majority matrix
+* Duels[i][j] is the number (or total weight) of voters strictly preferring Alts[i] to Alts[j]
+* It is computed once per profile and shared by all the pairwise rules
+* (Condorcet, Copeland, Schulze, Minimax, Ranked Pairs, Kemeny)
+ */
+
+type PairwiseMatrix struct {
+	Alts     []Alternative `json:"alts"`      // Alternatives, in the order of the rows and columns
+	Duels    [][]int       `json:"duels"`     // Duels[i][j]: voters preferring Alts[i] to Alts[j]
+	NbVoters int           `json:"nb-voters"` // Total weight of the voters
+	index    map[Alternative]int
+}
+
+// Returns an empty matrix for the given alternatives
+func newPairwiseMatrix(alts []Alternative, nbVoters int) *PairwiseMatrix {
+	pm := &PairwiseMatrix{
+		Alts:     alts,
+		Duels:    make([][]int, len(alts)),
+		NbVoters: nbVoters,
+		index:    make(map[Alternative]int, len(alts)),
+	}
+	for i, alt := range alts {
+		pm.Duels[i] = make([]int, len(alts))
+		pm.index[alt] = i
+	}
+	return pm
+}
+
+// Adds the duels of the given rankings to duels
+func addRankingDuels(duels [][]int, index map[Alternative]int, rankings [][]Alternative, weights []int) {
+	indexes := make([]int, 0)
+	for r, ranking := range rankings {
+		indexes = indexes[:0]
+		for _, alt := range ranking {
+			indexes = append(indexes, index[alt])
+		}
+		for i, a := range indexes {
+			for _, b := range indexes[i+1:] {
+				duels[a][b] += weights[r]
+			}
+		}
+	}
+}
+
+// Computes the pairwise matrix of a weighted profile
+func NewPairwiseMatrix(wp WeightedProfile) (*PairwiseMatrix, error) {
+	return NewPairwiseMatrixParallel(wp, 1)
+}
+
+// Computes the pairwise matrix of a weighted profile, the rankings being
+// split among nbWorkers goroutines (useful when there are many distinct rankings)
+func NewPairwiseMatrixParallel(wp WeightedProfile, nbWorkers int) (*PairwiseMatrix, error) {
+	err := checkWeightedProfile(wp)
+	if err != nil {
+		return nil, err
+	}
+	pm := newPairwiseMatrix(wp.Alternatives(), wp.NbVoters())
+	if nbWorkers > len(wp.Rankings) {
+		nbWorkers = len(wp.Rankings)
+	}
+	if nbWorkers <= 1 {
+		addRankingDuels(pm.Duels, pm.index, wp.Rankings, wp.Weights)
+		return pm, nil
+	}
+
+	// Each worker fills its own matrix, which are summed at the end
+	partials := make([]*PairwiseMatrix, nbWorkers)
+	var wg sync.WaitGroup
+	chunk := (len(wp.Rankings) + nbWorkers - 1) / nbWorkers
+	for w := 0; w < nbWorkers; w++ {
+		start, end := w*chunk, (w+1)*chunk
+		if end > len(wp.Rankings) {
+			end = len(wp.Rankings)
+		}
+		partials[w] = newPairwiseMatrix(pm.Alts, 0)
+		wg.Add(1)
+		go func(partial *PairwiseMatrix, start int, end int) {
+			defer wg.Done()
+			if start < end {
+				addRankingDuels(partial.Duels, partial.index, wp.Rankings[start:end], wp.Weights[start:end])
+			}
+		}(partials[w], start, end)
+	}
+	wg.Wait()
+	for _, partial := range partials {
+		for i := range pm.Duels {
+			for j := range pm.Duels[i] {
+				pm.Duels[i][j] += partial.Duels[i][j]
+			}
+		}
+	}
+	return pm, nil
+}
+
+// Computes the pairwise matrix of a weak profile on the alternatives alts
+// (ties and pairs of unranked alternatives count for no one)
+func NewWeakPairwiseMatrix(wp WeakProfile, alts []Alternative) (*PairwiseMatrix, error) {
+	err := checkWeakProfile(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	pm := newPairwiseMatrix(alts, len(wp))
+	for _, wo := range wp {
+		classes := weakClasses(wo)
+		for i, a := range alts {
+			for j, b := range alts {
+				if classOf(classes, a, len(wo)) < classOf(classes, b, len(wo)) {
+					pm.Duels[i][j]++
+				}
+			}
+		}
+	}
+	return pm, nil
+}
+
+// Number of voters preferring alt1 to alt2
+func (pm *PairwiseMatrix) Duel(alt1 Alternative, alt2 Alternative) int {
+	i, ok1 := pm.index[alt1]
+	j, ok2 := pm.index[alt2]
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return pm.Duels[i][j]
+}
+
+// Margin of alt1 against alt2: voters preferring alt1 minus voters preferring alt2
+func (pm *PairwiseMatrix) Margin(alt1 Alternative, alt2 Alternative) int {
+	return pm.Duel(alt1, alt2) - pm.Duel(alt2, alt1)
+}
+
+// Returns true if more voters prefer alt1 to alt2 than the reverse
+func (pm *PairwiseMatrix) Beats(alt1 Alternative, alt2 Alternative) bool {
+	return pm.Margin(alt1, alt2) > 0
+}
+
+// Number of duels won by each alternative
+func (pm *PairwiseMatrix) WinCounts() Count {
+	count := make(Count, len(pm.Alts))
+	for _, a := range pm.Alts {
+		count[a] = 0
+		for _, b := range pm.Alts {
+			if pm.Beats(a, b) {
+				count[a]++
+			}
+		}
+	}
+	return count
+}
+
+// Majority graph: each alternative is associated with the alternatives it beats
+func (pm *PairwiseMatrix) MajorityGraph() map[Alternative][]Alternative {
+	graph := make(map[Alternative][]Alternative, len(pm.Alts))
+	for _, a := range pm.Alts {
+		graph[a] = make([]Alternative, 0)
+		for _, b := range pm.Alts {
+			if pm.Beats(a, b) {
+				graph[a] = append(graph[a], b)
+			}
+		}
+	}
+	return graph
+}
@@ -0,0 +1,113 @@
+package comsoc
+
+import "fmt"
+
+/*
+* Positional Scoring Rules
+* Given a score vector s (s[0] >= s[1] >= ... >= s[m-1]), each voter gives
+* s[i] points to the alternative ranked at position i in their preferences
+* Borda and Majority are particular cases. Some other common vectors:
+* - Dowdall: 1, 1/2, 1/3, ..., 1/m
+* - Veto (anti-plurality): 1, ..., 1, 0
+* - k-approval: k times 1, then 0
+ */
+
+// Checks that the score vector can be used with nbAlts alternatives
+func CheckScoreVector(scoreVector []float64, nbAlts int) error {
+	if len(scoreVector) != nbAlts {
+		return fmt.Errorf("score vector of length %d does not match %d alternatives", len(scoreVector), nbAlts)
+	}
+	for i := 1; i < len(scoreVector); i++ {
+		if scoreVector[i] > scoreVector[i-1] {
+			return fmt.Errorf("score vector %v is not non-increasing", scoreVector)
+		}
+	}
+	return nil
+}
+
+// Returns the SWF of the positional scoring rule given by scoreVector
+func PositionalSWF(scoreVector []float64) func(Profile) (FloatCount, error) {
+	return func(p Profile) (FloatCount, error) {
+		return PositionalWeightedSWF(scoreVector)(Compress(p))
+	}
+}
+
+// Returns the SWF of the positional scoring rule given by scoreVector, for weighted profiles
+func PositionalWeightedSWF(scoreVector []float64) func(WeightedProfile) (FloatCount, error) {
+	return func(wp WeightedProfile) (FloatCount, error) {
+		err := checkWeightedProfile(wp)
+		if err != nil {
+			return nil, err
+		}
+		alts := wp.Alternatives()
+		err = CheckScoreVector(scoreVector, len(alts))
+		if err != nil {
+			return nil, err
+		}
+		// Number of voters ranking each alternative at each position, so that the scores
+		// only depend on these numbers (and not on the order of the rankings)
+		positions := make(map[Alternative][]int, len(alts))
+		for _, alt := range alts {
+			positions[alt] = make([]int, len(alts))
+		}
+		for r, votant := range wp.Rankings {
+			for i, alt := range votant {
+				positions[alt][i] += wp.Weights[r]
+			}
+		}
+		count := make(FloatCount, len(alts))
+		for _, alt := range alts {
+			count[alt] = 0
+			for i, nb := range positions[alt] {
+				count[alt] += scoreVector[i] * float64(nb)
+			}
+		}
+		return count, nil
+	}
+}
+
+// Returns the SCF of the positional scoring rule given by scoreVector
+func PositionalSCF(scoreVector []float64) func(Profile) ([]Alternative, error) {
+	return func(p Profile) ([]Alternative, error) {
+		return PositionalWeightedSCF(scoreVector)(Compress(p))
+	}
+}
+
+// Returns the SCF of the positional scoring rule given by scoreVector, for weighted profiles
+func PositionalWeightedSCF(scoreVector []float64) func(WeightedProfile) ([]Alternative, error) {
+	return func(wp WeightedProfile) ([]Alternative, error) {
+		count, err := PositionalWeightedSWF(scoreVector)(wp)
+		if err != nil {
+			return nil, err
+		}
+		return maxFloatCount(count), nil
+	}
+}
+
+// Score vector of the Borda rule for nbAlts alternatives
+func BordaVector(nbAlts int) []float64 {
+	res := make([]float64, nbAlts)
+	for i := range res {
+		res[i] = float64(nbAlts - 1 - i)
+	}
+	return res
+}
+
+// Score vector of the Dowdall rule for nbAlts alternatives
+func DowdallVector(nbAlts int) []float64 {
+	res := make([]float64, nbAlts)
+	for i := range res {
+		res[i] = 1 / float64(i+1)
+	}
+	return res
+}
+
+// Score vector of the k-approval rule for nbAlts alternatives
+// (1-approval is the plurality rule, and (nbAlts-1)-approval is the veto rule)
+func KApprovalVector(nbAlts int, k int) []float64 {
+	res := make([]float64, nbAlts)
+	for i := 0; i < k && i < nbAlts; i++ {
+		res[i] = 1
+	}
+	return res
+}
@@ -0,0 +1,409 @@
+package preflib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+/*
+* PrefLib files (www.preflib.org)
+* A file starts with metadata lines "# KEY: value" (DATA TYPE, NUMBER ALTERNATIVES, NUMBER VOTERS,
+* ALTERNATIVE NAME i...), followed by one line "count: order" per distinct order, e.g. "3: 2,{1,3},4".
+* The alternatives are numbered 1..m and the order gives indifference classes, best first, the
+* alternatives of a class being between braces. The data types are:
+* - soc: strict orders, complete
+* - soi: strict orders, incomplete (the alternatives not listed are unranked)
+* - toc: orders with ties, complete
+* - toi: orders with ties, incomplete
+* - cat: categorical preferences, each order giving the categories, best first (possibly empty: "{}")
+* The votes are read as a comsoc.WeakProfile, one weak order per voter
+ */
+
+// Data types
+const (
+	SOC = "soc"
+	SOI = "soi"
+	TOC = "toc"
+	TOI = "toi"
+	CAT = "cat"
+)
+
+// Content of a PrefLib file
+type Data struct {
+	Type     string                        // Data type (inferred from the votes when written, if empty)
+	Title    string                        // Title of the file (optional)
+	NbAlts   int                           // The alternatives are 1..NbAlts
+	Names    map[comsoc.Alternative]string // Name of each alternative (optional)
+	Votes    comsoc.WeakProfile            // Order of each voter, without empty classes
+	Approved []int                         // For cat files, number of alternatives in the first category of each vote (the approved ones)
+}
+
+// Data of a profile of strict rankings (soc, or soi if some rankings are truncated)
+func FromProfile(p comsoc.Profile, nbAlts int) *Data {
+	return &Data{NbAlts: nbAlts, Votes: comsoc.ToWeakProfile(p)}
+}
+
+// Data of a weak profile (soc, soi, toc or toi)
+func FromWeakProfile(wp comsoc.WeakProfile, nbAlts int) *Data {
+	return &Data{NbAlts: nbAlts, Votes: wp}
+}
+
+// Alternatives 1..NbAlts
+func (d *Data) Alts() []comsoc.Alternative {
+	alts := make([]comsoc.Alternative, d.NbAlts)
+	for i := range alts {
+		alts[i] = comsoc.Alternative(i + 1)
+	}
+	return alts
+}
+
+// Type of the votes: soc, soi, toc or toi
+func (d *Data) InferType() string {
+	var ties, incomplete bool
+	for _, wo := range d.Votes {
+		var nb int
+		for _, class := range wo {
+			nb += len(class)
+			ties = ties || len(class) > 1
+		}
+		incomplete = incomplete || nb < d.NbAlts
+	}
+	switch {
+	case ties && incomplete:
+		return TOI
+	case ties:
+		return TOC
+	case incomplete:
+		return SOI
+	}
+	return SOC
+}
+
+// Complete strict rankings of the voters, if the votes have neither ties nor unranked alternatives
+func (d *Data) Profile() (comsoc.Profile, error) {
+	if t := d.InferType(); t != SOC {
+		return nil, fmt.Errorf("the votes are of type %s, complete strict orders are expected", t)
+	}
+	p := make(comsoc.Profile, len(d.Votes))
+	for i, wo := range d.Votes {
+		for _, class := range wo {
+			p[i] = append(p[i], class[0])
+		}
+	}
+	return p, nil
+}
+
+// Rankings and approval thresholds of the voters of a cat file: each voter approves the alternatives of the
+// first category, and ranks all of them category by category (in increasing order within a category)
+func (d *Data) Approval() (comsoc.Profile, []int, error) {
+	if d.Approved == nil {
+		return nil, nil, fmt.Errorf("categorical preferences (cat) are expected")
+	}
+	p := make(comsoc.Profile, len(d.Votes))
+	for i, wo := range d.Votes {
+		for _, class := range wo {
+			sorted := append([]comsoc.Alternative{}, class...)
+			sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+			p[i] = append(p[i], sorted...)
+		}
+		if len(p[i]) != d.NbAlts {
+			return nil, nil, fmt.Errorf("vote %d does not put all the alternatives in a category", i+1)
+		}
+	}
+	return p, d.Approved, nil
+}
+
+// Reads a PrefLib file
+func ReadFile(path string) (*Data, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Reads PrefLib data
+func Read(r io.Reader) (*Data, error) {
+	return ReadLimit(r, -1)
+}
+
+// Reads PrefLib data of at most maxVoters voters (if maxVoters >= 0), stopping as soon as there are more
+// votes than allowed or announced, before they are expanded
+func ReadLimit(r io.Reader, maxVoters int) (*Data, error) {
+	d := &Data{Names: make(map[comsoc.Alternative]string)}
+	var nbVoters = -1
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			// metadata
+			kv := strings.SplitN(strings.TrimSpace(text[1:]), ":", 2)
+			if len(kv) < 2 {
+				continue
+			}
+			key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			var err error
+			switch {
+			case key == "DATA TYPE":
+				d.Type = strings.ToLower(value)
+			case key == "TITLE":
+				d.Title = value
+			case key == "NUMBER ALTERNATIVES":
+				d.NbAlts, err = strconv.Atoi(value)
+			case key == "NUMBER VOTERS":
+				nbVoters, err = strconv.Atoi(value)
+			case strings.HasPrefix(key, "ALTERNATIVE NAME "):
+				var i int
+				i, err = strconv.Atoi(strings.TrimPrefix(key, "ALTERNATIVE NAME "))
+				d.Names[comsoc.Alternative(i)] = value
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s is not a number", line, value)
+			}
+			continue
+		}
+
+		// order
+		if d.NbAlts < 1 {
+			return nil, fmt.Errorf("line %d: the number of alternatives should be given before the orders", line)
+		}
+		parts := strings.SplitN(text, ":", 2)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("line %d: \"count: order\" expected", line)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("line %d: the count should be a positive number", line)
+		}
+		if nbVoters >= 0 && len(d.Votes)+count > nbVoters {
+			return nil, fmt.Errorf("line %d: more than the %d voters announced", line, nbVoters)
+		}
+		if maxVoters >= 0 && len(d.Votes)+count > maxVoters {
+			return nil, fmt.Errorf("line %d: more than %d voters", line, maxVoters)
+		}
+		categories, err := parseOrder(parts[1], d.NbAlts)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		var wo comsoc.WeakOrder
+		for _, class := range categories {
+			if len(class) > 0 {
+				wo = append(wo, class)
+			}
+		}
+		for i := 0; i < count; i++ {
+			d.Votes = append(d.Votes, wo)
+			if d.Type == CAT {
+				d.Approved = append(d.Approved, len(categories[0]))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	switch d.Type {
+	case SOC, SOI, TOC, TOI, CAT:
+	default:
+		return nil, fmt.Errorf("unknown data type %q", d.Type)
+	}
+	if nbVoters >= 0 && nbVoters != len(d.Votes) {
+		return nil, fmt.Errorf("%d voters announced, %d found", nbVoters, len(d.Votes))
+	}
+	if d.Type != CAT {
+		if t := d.InferType(); !compatible(t, d.Type) {
+			return nil, fmt.Errorf("votes of type %s found in a file of type %s", t, d.Type)
+		}
+	}
+	return d, nil
+}
+
+// True if votes of type found can be stored in a file of type expected
+func compatible(found string, expected string) bool {
+	switch expected {
+	case SOC:
+		return found == SOC
+	case SOI:
+		return found == SOC || found == SOI
+	case TOC:
+		return found == SOC || found == TOC
+	}
+	return true
+}
+
+// Parses an order "1,{2,3},4" into its classes (including the empty ones "{}")
+func parseOrder(text string, nbAlts int) ([][]comsoc.Alternative, error) {
+	var res [][]comsoc.Alternative
+	seen := make(map[comsoc.Alternative]bool)
+	parseAlt := func(s string) (comsoc.Alternative, error) {
+		i, err := strconv.Atoi(strings.TrimSpace(s))
+		alt := comsoc.Alternative(i)
+		if err != nil || i < 1 || i > nbAlts {
+			return 0, fmt.Errorf("%q is not an alternative in [1, %d]", strings.TrimSpace(s), nbAlts)
+		}
+		if seen[alt] {
+			return 0, fmt.Errorf("alternative %d appears twice", i)
+		}
+		seen[alt] = true
+		return alt, nil
+	}
+	text = strings.TrimSpace(text)
+	for len(text) > 0 {
+		var token string
+		if text[0] == '{' {
+			end := strings.IndexByte(text, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed brace")
+			}
+			token, text = text[1:end], strings.TrimSpace(text[end+1:])
+			class := []comsoc.Alternative{}
+			if strings.TrimSpace(token) != "" {
+				for _, s := range strings.Split(token, ",") {
+					alt, err := parseAlt(s)
+					if err != nil {
+						return nil, err
+					}
+					class = append(class, alt)
+				}
+			}
+			res = append(res, class)
+		} else {
+			end := strings.IndexByte(text, ',')
+			if end < 0 {
+				end = len(text)
+			}
+			token, text = text[:end], text[end:]
+			alt, err := parseAlt(token)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, []comsoc.Alternative{alt})
+		}
+		if len(text) > 0 {
+			if text[0] != ',' {
+				return nil, fmt.Errorf("',' expected before %q", text)
+			}
+			text = strings.TrimSpace(text[1:])
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("empty order")
+	}
+	return res, nil
+}
+
+// Writes a PrefLib file
+func WriteFile(path string, d *Data) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = Write(f, d)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// Writes PrefLib data, the identical orders being grouped (most frequent first)
+func Write(w io.Writer, d *Data) error {
+	t := d.Type
+	if t == "" {
+		t = d.InferType()
+	}
+	if t != CAT && !compatible(d.InferType(), t) {
+		return fmt.Errorf("votes of type %s cannot be written in a file of type %s", d.InferType(), t)
+	}
+	if t == CAT && d.Approved != nil && len(d.Approved) != len(d.Votes) {
+		return fmt.Errorf("one number of approved alternatives is expected per vote")
+	}
+	if err := checkVotes(d); err != nil {
+		return err
+	}
+
+	// orders, with their counts
+	var orders []string
+	counts := make(map[string]int)
+	var nbCategories = 1
+	for i, wo := range d.Votes {
+		var classes []string
+		if t == CAT && d.Approved != nil && d.Approved[i] == 0 {
+			classes = append(classes, "{}") // no approved alternative
+		}
+		for _, class := range wo {
+			classes = append(classes, formatClass(class, t == CAT))
+		}
+		if len(classes) > nbCategories {
+			nbCategories = len(classes)
+		}
+		order := strings.Join(classes, ",")
+		if counts[order] == 0 {
+			orders = append(orders, order)
+		}
+		counts[order]++
+	}
+	sort.SliceStable(orders, func(i, j int) bool { return counts[orders[i]] > counts[orders[j]] })
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# TITLE: %s\n", d.Title)
+	fmt.Fprintf(bw, "# DATA TYPE: %s\n", t)
+	fmt.Fprintf(bw, "# NUMBER ALTERNATIVES: %d\n", d.NbAlts)
+	fmt.Fprintf(bw, "# NUMBER VOTERS: %d\n", len(d.Votes))
+	fmt.Fprintf(bw, "# NUMBER UNIQUE ORDERS: %d\n", len(orders))
+	if t == CAT {
+		fmt.Fprintf(bw, "# NUMBER CATEGORIES: %d\n", nbCategories)
+	}
+	for _, alt := range d.Alts() {
+		name, found := d.Names[alt]
+		if !found {
+			name = strconv.Itoa(int(alt))
+		}
+		fmt.Fprintf(bw, "# ALTERNATIVE NAME %d: %s\n", alt, name)
+	}
+	for _, order := range orders {
+		fmt.Fprintf(bw, "%d: %s\n", counts[order], order)
+	}
+	return bw.Flush()
+}
+
+// Checks that the votes only contain the alternatives 1..NbAlts, at most once
+func checkVotes(d *Data) error {
+	for i, wo := range d.Votes {
+		seen := make(map[comsoc.Alternative]bool)
+		for _, class := range wo {
+			if len(class) == 0 {
+				return fmt.Errorf("vote %d has an empty class", i+1)
+			}
+			for _, alt := range class {
+				if alt < 1 || int(alt) > d.NbAlts || seen[alt] {
+					return fmt.Errorf("vote %d is not correct", i+1)
+				}
+				seen[alt] = true
+			}
+		}
+	}
+	return nil
+}
+
+// Class "1" or "{1,2}" (always between braces for categories)
+func formatClass(class []comsoc.Alternative, braces bool) string {
+	s := make([]string, len(class))
+	for i, alt := range class {
+		s[i] = strconv.Itoa(int(alt))
+	}
+	if len(class) == 1 && !braces {
+		return s[0]
+	}
+	return "{" + strings.Join(s, ",") + "}"
+}
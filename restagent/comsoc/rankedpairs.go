@@ -0,0 +1,110 @@
+package comsoc
+
+import "sort"
+
+/*
+* Ranked Pairs (Tideman)
+* Every duel gives a pair (winner, loser) weighted by its margin
+* (number of voters preferring the winner minus number preferring the loser)
+* Pairs are locked in decreasing margin order, unless locking a pair
+* would create a cycle with the pairs already locked
+* The locked pairs form a total order which is the resulting ranking
+ */
+
+// Pair of alternatives resulting from a duel
+type rankedPair struct {
+	winner Alternative
+	loser  Alternative
+	margin int
+}
+
+// Returns true if to can be reached from from in the locked graph
+func isReachable(locked map[Alternative][]Alternative, from Alternative, to Alternative) bool {
+	visited := make(map[Alternative]bool, len(locked))
+	stack := []Alternative{from}
+	for len(stack) > 0 {
+		curr := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if curr == to {
+			return true
+		}
+		if visited[curr] {
+			continue
+		}
+		visited[curr] = true
+		stack = append(stack, locked[curr]...)
+	}
+	return false
+}
+
+// Note: the tie-break is used within the algorithm itself, to order pairs with equal margins
+// (and to orient exact ties), so that the result is deterministic
+func RankedPairsSWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error) {
+	return RankedPairsWeightedSWF_TieBreak(Compress(p), tieBreak)
+}
+
+func RankedPairsWeightedSWF_TieBreak(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, error) {
+	pm, err := NewPairwiseMatrix(wp)
+	if err != nil {
+		return nil, err
+	}
+	return rankedPairs(pm, tieBreak), nil
+}
+
+// Ranked Pairs ranking computed from the pairwise matrix
+func rankedPairs(pm *PairwiseMatrix, tieBreak []Alternative) []Alternative {
+	alts := pm.Alts
+	// Position of each alternative in the tie-break (the lower, the better)
+	tieBreakMap := make(map[Alternative]int, len(tieBreak))
+	for i, alt := range tieBreak {
+		tieBreakMap[alt] = i
+	}
+
+	// Compute all the pairs with their margin
+	pairs := make([]rankedPair, 0, len(alts)*(len(alts)-1)/2)
+	for i := 0; i < len(alts)-1; i++ {
+		for j := i + 1; j < len(alts); j++ {
+			a, b := alts[i], alts[j]
+			margin := pm.Margin(a, b)
+			if margin > 0 || (margin == 0 && tieBreakMap[a] < tieBreakMap[b]) {
+				pairs = append(pairs, rankedPair{a, b, margin})
+			} else {
+				pairs = append(pairs, rankedPair{b, a, -margin})
+			}
+		}
+	}
+
+	// Sort the pairs by decreasing margin. Equal margins are ordered by the tie-break:
+	// first the pair whose winner is the best, then the pair whose loser is the worst
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].margin != pairs[j].margin {
+			return pairs[i].margin > pairs[j].margin
+		}
+		if pairs[i].winner != pairs[j].winner {
+			return tieBreakMap[pairs[i].winner] < tieBreakMap[pairs[j].winner]
+		}
+		return tieBreakMap[pairs[i].loser] > tieBreakMap[pairs[j].loser]
+	})
+
+	// Lock the pairs which do not create a cycle
+	locked := make(map[Alternative][]Alternative, len(alts))
+	for _, pair := range pairs {
+		if !isReachable(locked, pair.loser, pair.winner) {
+			locked[pair.winner] = append(locked[pair.winner], pair.loser)
+		}
+	}
+
+	// Every pair is either locked or implied by locked pairs, so the number
+	// of alternatives reachable from an alternative gives its position
+	res := make([]Alternative, len(alts))
+	for _, a := range alts {
+		nbBeaten := 0
+		for _, b := range alts {
+			if a != b && isReachable(locked, a, b) {
+				nbBeaten++
+			}
+		}
+		res[len(alts)-1-nbBeaten] = a
+	}
+	return res
+}
@@ -0,0 +1,333 @@
+package comsoc
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+* Voting rules as values
+* A Rule gives its name, the format of the votes it expects, checks the options of a ballot
+* and computes its result. Rules are registered (see restagent.RegisterRule) and the server
+* only uses this interface, so that a new rule can be added without modifying the server
+ */
+
+// Format of the votes expected by a rule
+type BallotFormat int
+
+const (
+	RankingFormat  BallotFormat = iota // Ranking of all the alternatives (or weak orders if the ballot allows partial votes)
+	ApprovalFormat                     // Ranking and approval threshold
+	GradeFormat                        // Grade of each alternative
+)
+
+// Options specific to some voting methods, given at the creation of a ballot (all optional)
+type RuleOptions struct {
+	Variant      string    `json:"variant,omitempty"`       // Variant of the voting method (for minimax: winning-votes, margins or pairwise-opposition)
+	Alpha        *float64  `json:"alpha,omitempty"`         // Points given for a tied duel, in [0, 1] (for copeland)
+	ScoreVector  []float64 `json:"score-vector,omitempty"`  // Points given to each position of the preferences, non-increasing and of length #alts (for scoring)
+	Seats        int       `json:"seats,omitempty"`         // Size of the committee to elect, in [1, #alts] (for multi-winner rules)
+	MaxGrade     int       `json:"max-grade,omitempty"`     // Grades go from 0 to MaxGrade, which should be >= 1 (for cardinal rules)
+	AllowPartial bool      `json:"allow-partial,omitempty"` // Accept votes with ties and truncated rankings (for rules supporting it)
+	Fallback     string    `json:"fallback,omitempty"`      // Rule used when there is no Condorcet winner (for condorcet_completion)
+}
+
+// Names of the options, as in the JSON requests
+const (
+	OptionVariant      = "variant"
+	OptionAlpha        = "alpha"
+	OptionScoreVector  = "score-vector"
+	OptionSeats        = "seats"
+	OptionMaxGrade     = "max-grade"
+	OptionAllowPartial = "allow-partial"
+	OptionFallback     = "fallback"
+)
+
+// Returns the names of the options which are set
+func (o RuleOptions) Set() []string {
+	res := make([]string, 0)
+	if o.Variant != "" {
+		res = append(res, OptionVariant)
+	}
+	if o.Alpha != nil {
+		res = append(res, OptionAlpha)
+	}
+	if o.ScoreVector != nil {
+		res = append(res, OptionScoreVector)
+	}
+	if o.Seats != 0 {
+		res = append(res, OptionSeats)
+	}
+	if o.MaxGrade != 0 {
+		res = append(res, OptionMaxGrade)
+	}
+	if o.AllowPartial {
+		res = append(res, OptionAllowPartial)
+	}
+	if o.Fallback != "" {
+		res = append(res, OptionFallback)
+	}
+	return res
+}
+
+// Votes of a ballot. Only the fields matching the format of the rule (and AllowPartial) are filled
+type Votes struct {
+	Alts       []Alternative // Alternatives of the ballot
+	Profile    Profile       // Complete strict rankings
+	Thresholds []int         // Approval threshold of each ranking of Profile (ApprovalFormat)
+	Weak       WeakProfile   // Weak orders, if the ballot allows partial votes
+	Grades     GradeProfile  // Grades (GradeFormat)
+}
+
+// Result of a rule
+type RuleResult struct {
+	Winner    Alternative   // Winning alternative (0 if there is none)
+	Ranking   []Alternative // Ranking of the alternatives (may be nil, e.g. for Condorcet)
+	Committee []Alternative // Elected committee, for multi-winner rules
+	Rounds    []STVRound    // Tallies of each round, for multi-winner STV
+	Solver    string        // "exact" or "heuristic", for Kemeny, PAV and Chamberlin-Courant
+	DecidedBy string        // "condorcet" or the fallback rule, for Condorcet completion rules
+}
+
+type Rule interface {
+	Name() string
+	Format() BallotFormat
+	// Checks the options of a ballot with nbAlts alternatives (options the rule does not use are rejected)
+	CheckOptions(options RuleOptions, nbAlts int) error
+	// Computes the result, the tie-break being a strict order of all the alternatives
+	Compute(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, error)
+}
+
+// Rules which can explain their result implement this interface as well
+type Explainer interface {
+	// Computes the result (as Compute) and its explanation
+	Explain(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, Explanation, error)
+}
+
+// Rules giving a score to each alternative (the higher, the better) implement this interface as well
+type Scorer interface {
+	Scores(votes Votes, options RuleOptions) (FloatCount, error)
+}
+
+// Rules computing their margin of victory exactly implement this interface as well (see margin.go)
+type MarginComputer interface {
+	Margin(votes Votes, options RuleOptions, tieBreak []Alternative) (Margin, error)
+}
+
+// What the result of a rule gives
+type ResultKind int
+
+const (
+	ResultRanking   ResultKind = iota // Ranking of all the alternatives
+	ResultWinner                      // Winner only, without ranking (e.g. Condorcet)
+	ResultCommittee                   // Committee of options.Seats alternatives, without ranking
+)
+
+// Rules whose result is not a ranking of all the alternatives implement this interface as well.
+// FuncRule and TraceRule implement it with their Result field
+type ResultKindRule interface {
+	ResultKind() ResultKind
+}
+
+// Returns what the result of the rule gives (ResultRanking if it does not implement ResultKindRule)
+func RuleResultKind(rule Rule) ResultKind {
+	if rk, ok := rule.(ResultKindRule); ok {
+		return rk.ResultKind()
+	}
+	return ResultRanking
+}
+
+// Rules accepting weak orders and truncated rankings (option allow-partial) implement this interface as well
+// (see weak.go). FuncRule, TraceRule and ScoreRule implement it with their Partial field
+type PartialRule interface {
+	PartialPolicy() PartialPolicy
+}
+
+// Returns how the rule treats partial ballots (PartialUnsupported if it does not implement PartialRule)
+func RulePartialPolicy(rule Rule) PartialPolicy {
+	if pr, ok := rule.(PartialRule); ok {
+		return pr.PartialPolicy()
+	}
+	return PartialUnsupported
+}
+
+// Rules electing the Condorcet winner when there is one implement this interface as well (see margin.go)
+type CondorcetConsistentRule interface {
+	// Tells whether the rule elects the Condorcet winner with these options
+	CondorcetConsistent(options RuleOptions) bool
+}
+
+// Rule defined by functions, which is the simplest way to define a new rule
+type FuncRule struct {
+	RuleName     string
+	BallotFormat BallotFormat
+	Options      []string                                    // Options accepted by the rule, besides allow-partial
+	Partial      PartialPolicy                               // Treatment of partial ballots (allow-partial is accepted unless PartialUnsupported)
+	Result       ResultKind                                  // What the result gives (a ranking by default)
+	CheckFunc    func(options RuleOptions, nbAlts int) error // Checks the values of the options (may be nil)
+	ComputeFunc  func(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, error)
+}
+
+func (r FuncRule) Name() string {
+	return r.RuleName
+}
+
+func (r FuncRule) Format() BallotFormat {
+	return r.BallotFormat
+}
+
+func (r FuncRule) PartialPolicy() PartialPolicy {
+	return r.Partial
+}
+
+func (r FuncRule) ResultKind() ResultKind {
+	return r.Result
+}
+
+func (r FuncRule) CheckOptions(options RuleOptions, nbAlts int) error {
+	return checkRuleOptions(r.RuleName, options, nbAlts, r.Options, r.Partial, r.CheckFunc)
+}
+
+func (r FuncRule) Compute(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, error) {
+	return r.ComputeFunc(votes, options, tieBreak)
+}
+
+// Rule computed in rounds, explained by the trace of its rounds
+type TraceRule struct {
+	RuleName     string
+	BallotFormat BallotFormat
+	Options      []string                                    // Options accepted by the rule, besides allow-partial
+	Partial      PartialPolicy                               // Treatment of partial ballots (allow-partial is accepted unless PartialUnsupported)
+	Result       ResultKind                                  // What the result gives (a ranking by default)
+	CheckFunc    func(options RuleOptions, nbAlts int) error // Checks the values of the options (may be nil)
+	TraceFunc    func(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, Explanation, error)
+}
+
+func (r TraceRule) Name() string {
+	return r.RuleName
+}
+
+func (r TraceRule) Format() BallotFormat {
+	return r.BallotFormat
+}
+
+func (r TraceRule) PartialPolicy() PartialPolicy {
+	return r.Partial
+}
+
+func (r TraceRule) ResultKind() ResultKind {
+	return r.Result
+}
+
+func (r TraceRule) CheckOptions(options RuleOptions, nbAlts int) error {
+	return checkRuleOptions(r.RuleName, options, nbAlts, r.Options, r.Partial, r.CheckFunc)
+}
+
+func (r TraceRule) Compute(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, error) {
+	res, _, err := r.TraceFunc(votes, options, tieBreak)
+	return res, err
+}
+
+func (r TraceRule) Explain(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, Explanation, error) {
+	return r.TraceFunc(votes, options, tieBreak)
+}
+
+// Rule ranking the alternatives by decreasing score, ties being broken by the tie-break
+type ScoreRule struct {
+	RuleName     string
+	BallotFormat BallotFormat
+	Options      []string                                    // Options accepted by the rule, besides allow-partial
+	Partial      PartialPolicy                               // Treatment of partial ballots (allow-partial is accepted unless PartialUnsupported)
+	CheckFunc    func(options RuleOptions, nbAlts int) error // Checks the values of the options (may be nil)
+	ScoreFunc    func(votes Votes, options RuleOptions) (FloatCount, error)
+}
+
+func (r ScoreRule) Name() string {
+	return r.RuleName
+}
+
+func (r ScoreRule) Format() BallotFormat {
+	return r.BallotFormat
+}
+
+func (r ScoreRule) PartialPolicy() PartialPolicy {
+	return r.Partial
+}
+
+func (r ScoreRule) CheckOptions(options RuleOptions, nbAlts int) error {
+	return checkRuleOptions(r.RuleName, options, nbAlts, r.Options, r.Partial, r.CheckFunc)
+}
+
+func (r ScoreRule) Scores(votes Votes, options RuleOptions) (FloatCount, error) {
+	return r.ScoreFunc(votes, options)
+}
+
+func (r ScoreRule) Compute(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, error) {
+	res, _, err := r.Explain(votes, options, tieBreak)
+	return res, err
+}
+
+// The explanation is a single round giving the scores
+func (r ScoreRule) Explain(votes Votes, options RuleOptions, tieBreak []Alternative) (RuleResult, Explanation, error) {
+	count, err := r.ScoreFunc(votes, options)
+	if err != nil {
+		return RuleResult{}, nil, err
+	}
+	ranking, err := RankFloatCount(count, TieBreakFactory(tieBreak))
+	if err != nil {
+		return RuleResult{}, nil, err
+	}
+	res, err := RankingResult(ranking)
+	if err != nil {
+		return RuleResult{}, nil, err
+	}
+	explanation := Explanation{{
+		Round:        1,
+		Criterion:    r.RuleName + " score",
+		Tallies:      count,
+		Elected:      []Alternative{res.Winner},
+		TieBreakUsed: len(TiedGroups(count)[0]) > 1,
+	}}
+	return res, explanation, nil
+}
+
+// Checks that only the allowed options (and allow-partial if partial ballots are supported) are set,
+// then their values with check (if not nil)
+func checkRuleOptions(rule string, options RuleOptions, nbAlts int, allowed []string, partial PartialPolicy, check func(RuleOptions, int) error) error {
+	if partial != PartialUnsupported {
+		allowed = append(allowed[:len(allowed):len(allowed)], OptionAllowPartial)
+	}
+	err := CheckOnlyOptions(rule, options, allowed...)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		return check(options, nbAlts)
+	}
+	return nil
+}
+
+// Returns an error if an option which is not in allowed is set
+func CheckOnlyOptions(rule string, options RuleOptions, allowed ...string) error {
+	for _, name := range options.Set() {
+		var ok = false
+		for _, a := range allowed {
+			if a == name {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("option %s is not available for rule %s", name, rule)
+		}
+	}
+	return nil
+}
+
+// Result of a rule giving a ranking
+func RankingResult(ranking []Alternative) (RuleResult, error) {
+	if len(ranking) == 0 {
+		return RuleResult{}, errors.New("empty ranking")
+	}
+	return RuleResult{Winner: ranking[0], Ranking: ranking}, nil
+}
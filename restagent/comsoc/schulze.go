@@ -0,0 +1,93 @@
+package comsoc
+
+/*
+* Schulze Method (beatpath)
+* d[a][b] is the number of voters preferring a to b (same duels as the Condorcet winner)
+* A link a->b has strength d[a][b] if a wins the duel against b, 0 otherwise
+* The strength of a path is the strength of its weakest link, and
+* s[a][b] is the strength of the strongest path from a to b
+* a is ranked above b if s[a][b] > s[b][a]. This relation is transitive,
+* so the score of a candidate is the number of candidates it beats this way
+ */
+
+// Computes the strengths of the strongest paths between each pair of alternatives
+func strongestPaths(pm *PairwiseMatrix) map[Alternative]map[Alternative]int {
+	alts := pm.Alts
+	strength := make(map[Alternative]map[Alternative]int, len(alts))
+	for _, a := range alts {
+		strength[a] = make(map[Alternative]int, len(alts))
+	}
+	// Direct links, built from the pairwise matrix
+	for i, a := range alts {
+		for j, b := range alts {
+			if i != j && pm.Duels[i][j] > pm.Duels[j][i] {
+				strength[a][b] = pm.Duels[i][j]
+			}
+		}
+	}
+	// Floyd-Warshall variant: widest paths
+	for _, k := range alts {
+		for _, a := range alts {
+			if a == k {
+				continue
+			}
+			for _, b := range alts {
+				if b == k || b == a {
+					continue
+				}
+				viaK := strength[a][k]
+				if strength[k][b] < viaK {
+					viaK = strength[k][b]
+				}
+				if viaK > strength[a][b] {
+					strength[a][b] = viaK
+				}
+			}
+		}
+	}
+	return strength
+}
+
+// Schulze scores computed from the pairwise matrix
+func schulzeScores(pm *PairwiseMatrix) Count {
+	alts := pm.Alts
+	strength := strongestPaths(pm)
+	count := make(Count, len(alts))
+	for _, a := range alts {
+		count[a] = 0
+		for _, b := range alts {
+			if a != b && strength[a][b] > strength[b][a] {
+				count[a]++
+			}
+		}
+	}
+	return count
+}
+
+func SchulzeSWF(p Profile) (Count, error) {
+	return SchulzeWeightedSWF(Compress(p))
+}
+
+func SchulzeWeightedSWF(wp WeightedProfile) (Count, error) {
+	pm, err := NewPairwiseMatrix(wp)
+	if err != nil {
+		return nil, err
+	}
+	return schulzeScores(pm), nil
+}
+
+func SchulzeSCF(p Profile) (bestAlts []Alternative, err error) {
+	count, err := SchulzeSWF(p)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
+
+func SchulzeWeightedSCF(wp WeightedProfile) (bestAlts []Alternative, err error) {
+	count, err := SchulzeWeightedSWF(wp)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
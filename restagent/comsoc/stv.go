@@ -11,16 +11,20 @@ package comsoc
  */
 
 func STV_SWF(p Profile) (Count, error) {
-	ok := checkProfile(p)
+	return STVWeightedSWF(Compress(p))
+}
+
+func STVWeightedSWF(wp WeightedProfile) (Count, error) {
+	ok := checkWeightedProfile(wp)
 	if ok != nil {
 		return nil, ok
 	}
-	copyP := make(Profile, len(p)) // We copy the profile to be able to perform deletions without affecting the original
-	for i, votant := range p {
+	copyP := make(Profile, len(wp.Rankings)) // We copy the profile to be able to perform deletions without affecting the original
+	for i, votant := range wp.Rankings {
 		copyP[i] = make([]Alternative, len(votant))
 		copy(copyP[i], votant)
 	}
-	resMap := make(Count, len(p[0]))
+	resMap := make(Count, len(copyP[0]))
 	// We initialize the map to 0
 	for _, alt := range copyP[0] {
 		resMap[alt] = 0
@@ -32,16 +36,11 @@ func STV_SWF(p Profile) (Count, error) {
 		for _, alt := range copyP[0] {
 			comptMap[alt] = 0
 		}
-		for _, votant := range copyP {
-			_, ok := comptMap[votant[0]]
-			if ok {
-				comptMap[votant[0]]++
-			} else {
-				comptMap[votant[0]] = 1
-			}
+		for indP, votant := range copyP {
+			comptMap[votant[0]] += wp.Weights[indP]
 		}
 		// We have the scores for each candidate for this round
-		var miniCount int = len(copyP) + 1
+		var miniCount int = wp.NbVoters() + 1
 		var miniAlt Alternative
 		for alt, count := range comptMap {
 			if count < miniCount {
@@ -76,3 +75,11 @@ func STV_SCF(p Profile) (bestAlts []Alternative, err error) {
 	}
 	return maxCount(count), nil
 }
+
+func STVWeightedSCF(wp WeightedProfile) (bestAlts []Alternative, err error) {
+	count, err := STVWeightedSWF(wp)
+	if err != nil {
+		return nil, err
+	}
+	return maxCount(count), nil
+}
@@ -0,0 +1,154 @@
+package comsoc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+* Multi-winner Single Transferable Vote
+* A committee of seats candidates is elected using the Droop quota: floor(n/(seats+1)) + 1
+* In each round, the ballots are counted for their preferred hopeful candidate, with their weight
+* - Candidates reaching the quota are elected. Their surplus (votes above the quota) is
+*   transferred with the Gregory method: all the ballots counted for an elected candidate
+*   keep a fraction surplus/tally of their weight for the next rounds
+* - If no candidate reaches the quota, the candidate with the fewest votes is eliminated
+*   and its ballots are transferred with their full weight
+* When there are as many hopeful candidates as remaining seats, they are all elected
+* The tie-break is used within the rounds to order candidates with equal tallies
+ */
+
+// Tally and outcome of a round of STVCommittee
+type STVRound struct {
+	Round        int                     `json:"round"`                // Round number (from 1)
+	Tallies      map[Alternative]float64 `json:"tallies"`              // Weighted votes of each hopeful candidate
+	Elected      []Alternative           `json:"elected,omitempty"`    // Candidates elected at the end of this round
+	Eliminated   Alternative             `json:"eliminated,omitempty"` // Candidate eliminated at the end of this round (0 if none)
+	TieBreakUsed bool                    `json:"tie-break-used"`       // True if the tie-break chose among candidates with the same tally
+}
+
+// Precision used to compare weighted tallies
+const stvEpsilon = 1e-9
+
+func STVCommittee(p Profile, seats int, tieBreak []Alternative) (committee []Alternative, rounds []STVRound, err error) {
+	return STVWeightedCommittee(Compress(p), seats, tieBreak)
+}
+
+func STVWeightedCommittee(wp WeightedProfile, seats int, tieBreak []Alternative) (committee []Alternative, rounds []STVRound, err error) {
+	err = checkWeightedProfile(wp)
+	if err != nil {
+		return nil, nil, err
+	}
+	alts := wp.Alternatives()
+	if seats < 1 || seats > len(alts) {
+		return nil, nil, fmt.Errorf("%d seats cannot be filled with %d candidates", seats, len(alts))
+	}
+
+	quota := float64(wp.NbVoters()/(seats+1) + 1)
+	weights := make([]float64, len(wp.Rankings)) // Current weight of the ballots of each ranking
+	for i := range weights {
+		weights[i] = float64(wp.Weights[i])
+	}
+	hopeful := make(map[Alternative]bool, len(alts))
+	for _, alt := range alts {
+		hopeful[alt] = true
+	}
+
+	// Orders the candidates by decreasing tally, then by the tie-break
+	sortByTally := func(alts []Alternative, tallies map[Alternative]float64) {
+		sort.Slice(alts, func(i, j int) bool {
+			if math.Abs(tallies[alts[i]]-tallies[alts[j]]) > stvEpsilon {
+				return tallies[alts[i]] > tallies[alts[j]]
+			}
+			return rank(alts[i], tieBreak) < rank(alts[j], tieBreak)
+		})
+	}
+
+	committee = make([]Alternative, 0, seats)
+	for nbRound := 1; len(committee) < seats; nbRound++ {
+		// Count the weighted votes for the preferred hopeful candidate of each ballot
+		round := STVRound{Round: nbRound, Tallies: make(map[Alternative]float64, len(hopeful))}
+		for alt := range hopeful {
+			round.Tallies[alt] = 0
+		}
+		top := make([]Alternative, len(wp.Rankings)) // Candidate each ballot is counted for (0 if exhausted)
+		for i, votant := range wp.Rankings {
+			for _, alt := range votant {
+				if hopeful[alt] {
+					top[i] = alt
+					round.Tallies[alt] += weights[i]
+					break
+				}
+			}
+		}
+
+		hopefulAlts := make([]Alternative, 0, len(hopeful))
+		for alt := range hopeful {
+			// Rounding avoids displaying floating-point errors in the tallies
+			round.Tallies[alt] = math.Round(round.Tallies[alt]/stvEpsilon) * stvEpsilon
+			hopefulAlts = append(hopefulAlts, alt)
+		}
+		sortByTally(hopefulAlts, round.Tallies)
+
+		if len(hopefulAlts) <= seats-len(committee) {
+			// All the remaining candidates are elected
+			round.Elected = hopefulAlts
+		} else {
+			var reached []Alternative // Candidates reaching the quota
+			for _, alt := range hopefulAlts {
+				if round.Tallies[alt] >= quota-stvEpsilon {
+					reached = append(reached, alt)
+				}
+			}
+			round.Elected = reached
+			if len(reached) > seats-len(committee) {
+				// There are not enough seats: the tie-break matters if the first candidate left has the tally of the last one elected
+				round.Elected = reached[:seats-len(committee)]
+				round.TieBreakUsed = math.Abs(round.Tallies[reached[len(round.Elected)]]-round.Tallies[reached[len(round.Elected)-1]]) <= stvEpsilon
+			}
+		}
+
+		if len(round.Elected) > 0 {
+			for _, alt := range round.Elected {
+				// Gregory transfer of the surplus
+				ratio := (round.Tallies[alt] - quota) / round.Tallies[alt]
+				if ratio < 0 {
+					ratio = 0
+				}
+				for i := range wp.Rankings {
+					if top[i] == alt {
+						weights[i] *= ratio
+					}
+				}
+				delete(hopeful, alt)
+				committee = append(committee, alt)
+			}
+		} else {
+			// The last candidate (by tally, then tie-break) is eliminated
+			round.Eliminated = hopefulAlts[len(hopefulAlts)-1]
+			round.TieBreakUsed = math.Abs(round.Tallies[round.Eliminated]-round.Tallies[hopefulAlts[len(hopefulAlts)-2]]) <= stvEpsilon
+			delete(hopeful, round.Eliminated)
+		}
+		rounds = append(rounds, round)
+	}
+	return committee, rounds, nil
+}
+
+// Explanation of the rounds of STVCommittee
+func STVCommitteeExplanation(rounds []STVRound) Explanation {
+	res := make(Explanation, len(rounds))
+	for i, round := range rounds {
+		res[i] = ExplanationRound{
+			Round:        round.Round,
+			Criterion:    "weighted first places",
+			Tallies:      round.Tallies,
+			Elected:      round.Elected,
+			TieBreakUsed: round.TieBreakUsed,
+		}
+		if round.Eliminated != 0 {
+			res[i].Eliminated = []Alternative{round.Eliminated}
+		}
+	}
+	return res
+}
@@ -2,6 +2,8 @@ package comsoc
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 )
 
 ///// Tie breakers
@@ -11,7 +13,8 @@ import (
  * For this purpose, tie-breaking functions are used
  * which, given a set of alternatives, return the best one.
  * They respect the following signature
- * (an error may occur if the slice of alternatives is empty):
+ * (an error may occur if the slice of alternatives is empty,
+ * or if one of them is missing from the strict order):
 **/
 func TieBreakFactory(strictOrder []Alternative) func([]Alternative) (Alternative, error) {
 	// The provided alternatives are used to break ties -> strict order
@@ -28,6 +31,9 @@ func TieBreakFactory(strictOrder []Alternative) func([]Alternative) (Alternative
 			var maxVal int
 			var maxAlt Alternative
 			for _, alt := range alts {
+				if order[alt] == 0 {
+					return -1, fmt.Errorf("alternative %d is missing from the tie-break", alt)
+				}
 				if order[alt] > maxVal {
 					maxVal = order[alt]
 					maxAlt = alt
@@ -102,6 +108,64 @@ func SWFFactory(swf func(p Profile) (Count, error), tieBreaker func([]Alternativ
 		return res, nil
 	}
 }
+
+// Same as SWFFactory, for SWFs giving non-integer scores
+func FloatSWFFactory(swf func(p Profile) (FloatCount, error), tieBreaker func([]Alternative) (Alternative, error)) func(Profile) ([]Alternative, error) {
+	return func(p Profile) ([]Alternative, error) {
+		count, err := swf(p)
+		if err != nil {
+			return nil, err
+		}
+		return RankFloatCount(count, tieBreaker)
+	}
+}
+
+// Orders the alternatives by decreasing score, ties being broken by tieBreaker
+func RankFloatCount(count FloatCount, tieBreaker func([]Alternative) (Alternative, error)) ([]Alternative, error) {
+	// Distinct scores, sorted in decreasing order
+	invCount := make(map[float64][]Alternative, len(count)) // dict {score: [candidates]}
+	scores := make([]float64, 0, len(count))
+	for alt, score := range count {
+		if _, ok := invCount[score]; !ok {
+			scores = append(scores, score)
+		}
+		invCount[score] = append(invCount[score], alt)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+
+	res := make([]Alternative, 0, len(count))
+	for _, score := range scores {
+		tab := invCount[score]
+		// As long as there are multiple tied elements, the best one
+		// according to the tiebreaker is added to res
+		for len(tab) > 1 {
+			best, err := tieBreaker(tab)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, best)
+			for i, alt := range tab {
+				if alt == best {
+					tab[i] = tab[len(tab)-1]
+					tab = tab[:len(tab)-1]
+					break
+				}
+			}
+		}
+		res = append(res, tab[0])
+	}
+	return res, nil
+}
+
+// Converts an integer count into a float count
+func ToFloatCount(count Count) FloatCount {
+	res := make(FloatCount, len(count))
+	for alt, score := range count {
+		res[alt] = float64(score)
+	}
+	return res
+}
+
 func SCFFactory(scf func(p Profile) ([]Alternative, error), tieBreaker func([]Alternative) (Alternative, error)) func(Profile) (Alternative, error) {
 	// Applies the scf function on the profile then breaks ties with tieBreaker. Returns the function that applies scf but without ties
 	return func(p Profile) (Alternative, error) {
@@ -116,7 +180,16 @@ func SCFFactory(scf func(p Profile) ([]Alternative, error), tieBreaker func([]Al
 
 // Note: it is necessary to create a specific SWF function with a particular Tie-break for approval because the threshold must be taken into account
 func MakeApprovalRankingWithTieBreak(p Profile, threshold []int, tieBreaker func([]Alternative) (Alternative, error)) ([]Alternative, error) {
-	count, err := ApprovalSWF(p, threshold)
+	wp, wThresholds, err := CompressApproval(p, threshold)
+	if err != nil {
+		return nil, err
+	}
+	return MakeWeightedApprovalRankingWithTieBreak(wp, wThresholds, tieBreaker)
+}
+
+// Same as MakeApprovalRankingWithTieBreak for a weighted profile
+func MakeWeightedApprovalRankingWithTieBreak(wp WeightedProfile, threshold []int, tieBreaker func([]Alternative) (Alternative, error)) ([]Alternative, error) {
+	count, err := ApprovalWeightedSWF(wp, threshold)
 	if err != nil {
 		return nil, err
 	}
@@ -179,15 +252,30 @@ func MakeApprovalRankingWithTieBreak(p Profile, threshold []int, tieBreaker func
 
 // Note: We need to create a specific SWF function with a tie-break for STV because the tie-breaking process is different. We use the tie-break within the algorithm itself.
 func STV_SWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error) {
+	return STVWeightedSWF_TieBreak(Compress(p), tieBreak)
+}
+
+func STVWeightedSWF_TieBreak(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, error) {
+	ranking, _, err := STVWeightedExplain(wp, tieBreak)
+	return ranking, err
+}
+
+// Same as STV_SWF_TieBreak, with the trace of the rounds
+func STVExplain(p Profile, tieBreak []Alternative) ([]Alternative, Explanation, error) {
+	return STVWeightedExplain(Compress(p), tieBreak)
+}
+
+func STVWeightedExplain(wp WeightedProfile, tieBreak []Alternative) ([]Alternative, Explanation, error) {
 	// Check if the profile is valid
-	ok := checkProfile(p)
+	ok := checkWeightedProfile(wp)
 	if ok != nil {
-		return nil, ok
+		return nil, nil, ok
 	}
+	var explanation Explanation
 
 	// Create a copy of the profile to avoid modifying the original
-	copyP := make(Profile, len(p))
-	for i, votant := range p {
+	copyP := make(Profile, len(wp.Rankings))
+	for i, votant := range wp.Rankings {
 		copyP[i] = make([]Alternative, len(votant))
 		copy(copyP[i], votant)
 	}
@@ -199,7 +287,7 @@ func STV_SWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error)
 	}
 
 	// Initialize the result map
-	resMap := make(Count, len(p[0]))
+	resMap := make(Count, len(copyP[0]))
 	for _, alt := range copyP[0] {
 		resMap[alt] = 0
 	}
@@ -212,16 +300,11 @@ func STV_SWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error)
 		for _, alt := range copyP[0] {
 			comptMap[alt] = 0
 		}
-		for _, votant := range copyP {
-			_, ok := comptMap[votant[0]]
-			if ok {
-				comptMap[votant[0]]++
-			} else {
-				comptMap[votant[0]] = 1
-			}
+		for indP, votant := range copyP {
+			comptMap[votant[0]] += wp.Weights[indP]
 		}
 		// Get the scores for this round
-		var miniCount int = len(copyP) + 1
+		var miniCount int = wp.NbVoters() + 1
 		miniAlts := make([]Alternative, 0)
 		for alt, count := range comptMap {
 			if count < miniCount {
@@ -240,6 +323,7 @@ func STV_SWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error)
 				miniAlt = alt
 			}
 		}
+		explanation.addRound("first places", comptMap, nil, []Alternative{miniAlt}, len(miniAlts) > 1)
 		// Eliminate the selected candidate from all votes
 		for indP, votant := range copyP {
 			var found bool
@@ -265,5 +349,5 @@ func STV_SWF_TieBreak(p Profile, tieBreak []Alternative) ([]Alternative, error)
 	for alt, score := range resMap {
 		res[len(resMap)-1-score] = alt
 	}
-	return res, nil
+	return res, explanation, nil
 }
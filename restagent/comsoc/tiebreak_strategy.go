@@ -0,0 +1,158 @@
+package comsoc
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+/*
+* Tie-breaking strategies
+* The rules break ties with a strict order of the alternatives (the tie-break). Besides the
+* order fixed at the creation of the ballot, this order can be:
+* - random, drawn from a seed which is published with the result so that it can be replayed
+* - given by a ballot: the first one, or one drawn at random from a seed
+* - given by a second-order rule, e.g. ties of Copeland broken by the Borda scores
+* In the "report" mode, no order is forced: the groups of tied alternatives are returned,
+* which is only possible for rules giving scores (Scorer)
+ */
+
+const (
+	TieBreakFixed        = "fixed"         // Order given at the creation of the ballot (default)
+	TieBreakRandom       = "random"        // Random order drawn from the seed
+	TieBreakFirstBallot  = "first-ballot"  // Order of the first ballot
+	TieBreakRandomBallot = "random-ballot" // Order of a ballot drawn from the seed
+	TieBreakRule         = "rule"          // Ranking of the second-order rule
+	TieBreakReport       = "report"        // Tied groups are returned instead of a ranking
+)
+
+var TieBreakStrategies = []string{TieBreakFixed, TieBreakRandom, TieBreakFirstBallot, TieBreakRandomBallot, TieBreakRule, TieBreakReport}
+
+// Tie-breaking strategy chosen at the creation of a ballot (all optional)
+type TieBreakOptions struct {
+	Strategy        string `json:"tie-break-strategy,omitempty"` // One of TieBreakStrategies (fixed by default)
+	Seed            *int64 `json:"seed,omitempty"`               // Seed of the random strategies (drawn by the server if not given)
+	SecondOrderRule string `json:"second-order-rule,omitempty"`  // Rule breaking the ties (for the rule strategy)
+}
+
+// Returns true if the strategy draws the tie-break from a seed
+func (o TieBreakOptions) UsesSeed() bool {
+	return o.Strategy == TieBreakRandom || o.Strategy == TieBreakRandomBallot
+}
+
+// Random strict order of the alternatives, always the same for a given seed
+func RandomTieBreak(alts []Alternative, seed int64) []Alternative {
+	perm := rand.New(rand.NewSource(seed)).Perm(len(alts))
+	res := make([]Alternative, len(alts))
+	for i, ind := range perm {
+		res[i] = alts[ind]
+	}
+	return res
+}
+
+// Completes a (possibly truncated) ranking into a strict order of the alternatives of tieBreak,
+// the missing alternatives being ordered by tieBreak
+func completeTieBreak(ranking []Alternative, tieBreak []Alternative) []Alternative {
+	res := make([]Alternative, 0, len(tieBreak))
+	seen := make(map[Alternative]bool, len(tieBreak))
+	for _, alt := range ranking {
+		if !seen[alt] {
+			res = append(res, alt)
+			seen[alt] = true
+		}
+	}
+	for _, alt := range tieBreak {
+		if !seen[alt] {
+			res = append(res, alt)
+		}
+	}
+	return res
+}
+
+// Number of votes, whatever their format
+func (v Votes) NbVotes() int {
+	switch {
+	case len(v.Grades) > 0:
+		return len(v.Grades)
+	case len(v.Weak) > 0:
+		return len(v.Weak)
+	}
+	return len(v.Profile)
+}
+
+// Strict order of the alternatives given by the i-th vote, its ties being broken by tieBreak
+// (by decreasing grade for grades, class by class for weak orders)
+func (v Votes) BallotTieBreak(i int, tieBreak []Alternative) []Alternative {
+	switch {
+	case len(v.Grades) > 0:
+		order := append([]Alternative{}, tieBreak...)
+		sort.SliceStable(order, func(a, b int) bool { return v.Grades[i][order[a]] > v.Grades[i][order[b]] })
+		return order
+	case len(v.Weak) > 0:
+		var ranking []Alternative
+		for _, class := range v.Weak[i] {
+			ranking = append(ranking, tieBreakOrderOf(class, tieBreak)...)
+		}
+		return completeTieBreak(ranking, tieBreak)
+	}
+	return completeTieBreak(v.Profile[i], tieBreak)
+}
+
+// Alternatives of class, in the order of tieBreak
+func tieBreakOrderOf(class []Alternative, tieBreak []Alternative) []Alternative {
+	res := append([]Alternative{}, class...)
+	sort.Slice(res, func(a, b int) bool { return rank(res[a], tieBreak) < rank(res[b], tieBreak) })
+	return res
+}
+
+// Tie-break given by the first vote
+func FirstBallotTieBreak(votes Votes, tieBreak []Alternative) ([]Alternative, error) {
+	if votes.NbVotes() == 0 {
+		return nil, errors.New("no votes submitted")
+	}
+	return votes.BallotTieBreak(0, tieBreak), nil
+}
+
+// Tie-break given by a vote drawn from the seed
+func RandomBallotTieBreak(votes Votes, seed int64, tieBreak []Alternative) ([]Alternative, error) {
+	if votes.NbVotes() == 0 {
+		return nil, errors.New("no votes submitted")
+	}
+	i := rand.New(rand.NewSource(seed)).Intn(votes.NbVotes())
+	return votes.BallotTieBreak(i, tieBreak), nil
+}
+
+// Groups of alternatives with the same score, by decreasing score
+// (the alternatives of a group being in increasing order)
+func TiedGroups(count FloatCount) [][]Alternative {
+	alts := make([]Alternative, 0, len(count))
+	for alt := range count {
+		alts = append(alts, alt)
+	}
+	sort.Slice(alts, func(i, j int) bool {
+		if count[alts[i]] != count[alts[j]] {
+			return count[alts[i]] > count[alts[j]]
+		}
+		return alts[i] < alts[j]
+	})
+	var groups [][]Alternative
+	for i, alt := range alts {
+		if i == 0 || count[alt] != count[alts[i-1]] {
+			groups = append(groups, []Alternative{})
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], alt)
+	}
+	return groups
+}
+
+// Groups of tied alternatives (of at least 2 alternatives) ordered as in ranking, i.e. the
+// ties which were broken by the tie-break to obtain ranking
+func BrokenTies(groups [][]Alternative, ranking []Alternative) [][]Alternative {
+	var res [][]Alternative
+	for _, group := range groups {
+		if len(group) > 1 {
+			res = append(res, tieBreakOrderOf(group, ranking))
+		}
+	}
+	return res
+}
@@ -0,0 +1,192 @@
+package comsoc
+
+/*
+* Tournament solutions
+* When there is no Condorcet winner, these sets give the alternatives which are "close" to being one,
+* using the majority graph of the pairwise matrix (a beats b if more voters prefer a to b than the reverse):
+* - Smith set (top cycle): smallest non-empty set whose members all beat every alternative outside it
+* - Schwartz set: union of the minimal sets that are not beaten from outside
+* - uncovered set: alternatives not covered, a covering b if a beats b and every alternative beaten by b
+* - Banks set: maximal elements of the maximal chains (sets totally ordered by the majority graph, that
+*   no other alternative beats entirely)
+* They all contain the Condorcet winner alone when it exists. Schwartz ⊆ Smith, and when
+* there is no tied duel (e.g. odd number of voters), Schwartz = Smith and Banks ⊆ uncovered ⊆ Smith.
+* The Banks set is only computed up to BanksLimit alternatives, as it is exponential
+ */
+
+// Maximum number of alternatives for which the Banks set is computed by NewTournamentSets
+const BanksLimit = 15
+
+// Sets returned by the tournament solutions
+type TournamentSets struct {
+	Smith     []Alternative `json:"smith"`
+	Schwartz  []Alternative `json:"schwartz"`
+	Uncovered []Alternative `json:"uncovered"`
+	Banks     []Alternative `json:"banks,omitempty"` // nil beyond BanksLimit alternatives
+}
+
+// Computes all the tournament solutions of the pairwise matrix (the Banks set up to BanksLimit alternatives)
+func NewTournamentSets(pm *PairwiseMatrix) TournamentSets {
+	sets := TournamentSets{
+		Smith:     SmithSet(pm),
+		Schwartz:  SchwartzSet(pm),
+		Uncovered: UncoveredSet(pm),
+	}
+	if len(pm.Alts) <= BanksLimit {
+		sets.Banks = BanksSet(pm)
+	}
+	return sets
+}
+
+// Transitive closure of the relation given on the indexes of the alternatives
+func transitiveClosure(m int, relation func(int, int) bool) [][]bool {
+	reach := make([][]bool, m)
+	for i := range reach {
+		reach[i] = make([]bool, m)
+		for j := range reach[i] {
+			reach[i][j] = i == j || relation(i, j)
+		}
+	}
+	for k := 0; k < m; k++ {
+		for i := 0; i < m; i++ {
+			if !reach[i][k] {
+				continue
+			}
+			for j := 0; j < m; j++ {
+				if reach[k][j] {
+					reach[i][j] = true
+				}
+			}
+		}
+	}
+	return reach
+}
+
+// Returns true if Alts[i] beats Alts[j]
+func (pm *PairwiseMatrix) beatsIndex(i int, j int) bool {
+	return pm.Duels[i][j] > pm.Duels[j][i]
+}
+
+// The Smith set contains the alternatives reaching all the others by a path of
+// alternatives each one not being beaten by the next one
+func SmithSet(pm *PairwiseMatrix) []Alternative {
+	reach := transitiveClosure(len(pm.Alts), func(i int, j int) bool {
+		return !pm.beatsIndex(j, i)
+	})
+	res := make([]Alternative, 0)
+	for i, a := range pm.Alts {
+		var all = true
+		for j := range pm.Alts {
+			if !reach[i][j] {
+				all = false
+				break
+			}
+		}
+		if all {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// The Schwartz set contains the alternatives a such that every alternative reaching a
+// by a path of majority defeats is reached back by a
+func SchwartzSet(pm *PairwiseMatrix) []Alternative {
+	reach := transitiveClosure(len(pm.Alts), pm.beatsIndex)
+	res := make([]Alternative, 0)
+	for i, a := range pm.Alts {
+		var undominated = true
+		for j := range pm.Alts {
+			if reach[j][i] && !reach[i][j] {
+				undominated = false
+				break
+			}
+		}
+		if undominated {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// Returns true if Alts[i] covers Alts[j]: it beats Alts[j] and all the alternatives beaten by Alts[j]
+func (pm *PairwiseMatrix) coversIndex(i int, j int) bool {
+	if !pm.beatsIndex(i, j) {
+		return false
+	}
+	for k := range pm.Alts {
+		if pm.beatsIndex(j, k) && !pm.beatsIndex(i, k) {
+			return false
+		}
+	}
+	return true
+}
+
+func UncoveredSet(pm *PairwiseMatrix) []Alternative {
+	res := make([]Alternative, 0)
+	for j, b := range pm.Alts {
+		var covered = false
+		for i := range pm.Alts {
+			if pm.coversIndex(i, j) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// Note: the chains are enumerated by a depth-first search, which is exponential in the worst case
+// (but the search stops as soon as a maximal chain is found for the alternative)
+func BanksSet(pm *PairwiseMatrix) []Alternative {
+	res := make([]Alternative, 0)
+	for i, a := range pm.Alts {
+		if pm.topOfMaximalChain([]int{i}) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// Returns true if the chain (ordered from its maximal element) can be extended
+// downwards into a chain that no alternative beats entirely
+func (pm *PairwiseMatrix) topOfMaximalChain(chain []int) bool {
+	var dominated = false
+	for k := range pm.Alts {
+		if pm.beatsAll(k, chain) {
+			dominated = true
+			break
+		}
+	}
+	if !dominated {
+		return true
+	}
+	// Alternatives beaten by all the members of the chain can be added at its bottom
+	for k := range pm.Alts {
+		if pm.beatenByAll(k, chain) && pm.topOfMaximalChain(append(chain[:len(chain):len(chain)], k)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pm *PairwiseMatrix) beatsAll(k int, chain []int) bool {
+	for _, i := range chain {
+		if !pm.beatsIndex(k, i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pm *PairwiseMatrix) beatenByAll(k int, chain []int) bool {
+	for _, i := range chain {
+		if !pm.beatsIndex(i, k) {
+			return false
+		}
+	}
+	return true
+}
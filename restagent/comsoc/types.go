@@ -3,3 +3,16 @@ package comsoc
 type Alternative int
 type Profile [][]Alternative
 type Count map[Alternative]int
+type FloatCount map[Alternative]float64
+type GradeProfile []map[Alternative]int
+
+// Ranking with ties: indifference classes, best class first.
+// Alternatives which appear in no class are unranked (truncated ranking)
+type WeakOrder [][]Alternative
+type WeakProfile []WeakOrder
+
+// Profile where each distinct ranking is given once, with its weight
+type WeightedProfile struct {
+	Rankings [][]Alternative // Rankings given by the voters
+	Weights  []int           // Weights[i] is the number (or total weight) of the voters giving Rankings[i]
+}
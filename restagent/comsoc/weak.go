@@ -0,0 +1,193 @@
+package comsoc
+
+import "errors"
+
+/*
+* Weak orders and truncated rankings
+* A WeakOrder gives indifference classes, best first, and may leave some alternatives
+* unranked. Each rule declares how it treats such ballots with a PartialPolicy (see PartialRule,
+* the rules whose policy is PartialUnsupported refusing ballots which allow them):
+* - positional rules average the scores of the positions covered by a class, and the
+*   unranked alternatives share the remaining positions as a last class
+* - pairwise rules count a voter for a against b only if a is in a strictly better class
+*   than b. Unranked alternatives are below all the ranked ones and tied among themselves
+ */
+
+// Treatment of partial ballots by a rule (given by the Partial field of FuncRule, TraceRule and ScoreRule)
+type PartialPolicy int
+
+const (
+	PartialUnsupported PartialPolicy = iota // Complete strict rankings are required
+	PartialAveraged                         // Positional rules, with averaged scores for tied positions
+	PartialPairwise                         // Pairwise rules, ties and unranked pairs counting for no one
+)
+
+// Associates each ranked alternative with the index of its class
+func weakClasses(wo WeakOrder) map[Alternative]int {
+	classes := make(map[Alternative]int)
+	for i, class := range wo {
+		for _, alt := range class {
+			classes[alt] = i
+		}
+	}
+	return classes
+}
+
+// Index of the class of alt, unranked alternatives being in the last class (nbClasses)
+func classOf(classes map[Alternative]int, alt Alternative, nbClasses int) int {
+	if c, ok := classes[alt]; ok {
+		return c
+	}
+	return nbClasses
+}
+
+// Checks the given weak profile, e.g., that each ballot only contains alternatives of alts, at most once
+func checkWeakProfile(wp WeakProfile, alts []Alternative) error {
+	if len(wp) < 1 {
+		return errors.New("no votes submitted")
+	}
+	if len(alts) < 2 {
+		return errors.New("less than 2 candidates")
+	}
+	for _, wo := range wp {
+		seen := make(map[Alternative]bool, len(alts))
+		for _, class := range wo {
+			if len(class) == 0 {
+				return errors.New("weak profile is not correct: empty indifference class")
+			}
+			for _, alt := range class {
+				if seen[alt] || rank(alt, alts) == -1 {
+					return errors.New("weak profile is not correct")
+				}
+				seen[alt] = true
+			}
+		}
+	}
+	return nil
+}
+
+// Converts a profile of strict rankings into a weak profile (one alternative per class)
+func ToWeakProfile(p Profile) WeakProfile {
+	wp := make(WeakProfile, len(p))
+	for i, votant := range p {
+		wp[i] = make(WeakOrder, len(votant))
+		for j, alt := range votant {
+			wp[i][j] = []Alternative{alt}
+		}
+	}
+	return wp
+}
+
+// Returns the SWF of the positional scoring rule given by scoreVector on weak profiles:
+// each class gets the average score of the positions it covers
+func PositionalWeakSWF(scoreVector []float64) func(WeakProfile, []Alternative) (FloatCount, error) {
+	return func(wp WeakProfile, alts []Alternative) (FloatCount, error) {
+		err := checkWeakProfile(wp, alts)
+		if err != nil {
+			return nil, err
+		}
+		err = CheckScoreVector(scoreVector, len(alts))
+		if err != nil {
+			return nil, err
+		}
+		count := make(FloatCount, len(alts))
+		for _, alt := range alts {
+			count[alt] = 0
+		}
+		// Average score of the positions from start (included) to end (excluded)
+		average := func(start int, end int) float64 {
+			var sum float64
+			for _, s := range scoreVector[start:end] {
+				sum += s
+			}
+			return sum / float64(end-start)
+		}
+		for _, wo := range wp {
+			var pos int
+			ranked := make(map[Alternative]bool, len(alts))
+			for _, class := range wo {
+				avg := average(pos, pos+len(class))
+				for _, alt := range class {
+					count[alt] += avg
+					ranked[alt] = true
+				}
+				pos += len(class)
+			}
+			if pos < len(alts) {
+				avg := average(pos, len(alts))
+				for _, alt := range alts {
+					if !ranked[alt] {
+						count[alt] += avg
+					}
+				}
+			}
+		}
+		return count, nil
+	}
+}
+
+func BordaWeakSWF(wp WeakProfile, alts []Alternative) (FloatCount, error) {
+	return PositionalWeakSWF(BordaVector(len(alts)))(wp, alts)
+}
+
+// The point of a voter is shared among the alternatives of their first class
+func MajorityWeakSWF(wp WeakProfile, alts []Alternative) (FloatCount, error) {
+	return PositionalWeakSWF(KApprovalVector(len(alts), 1))(wp, alts)
+}
+
+func CondorcetWinnerWeak(wp WeakProfile, alts []Alternative) (bestAlts []Alternative, err error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	return condorcetWinner(pm), nil
+}
+
+func CopelandWeakSWF(wp WeakProfile, alts []Alternative) (Count, error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	return copelandScores(pm), nil
+}
+
+func CopelandAlphaWeakSWF(wp WeakProfile, alts []Alternative, alpha float64) (FloatCount, error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	return copelandAlphaScores(pm, alpha), nil
+}
+
+func SchulzeWeakSWF(wp WeakProfile, alts []Alternative) (Count, error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	return schulzeScores(pm), nil
+}
+
+func MinimaxWeakSWF(wp WeakProfile, alts []Alternative, variant string) (Count, error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	return minimaxScores(pm, variant)
+}
+
+func RankedPairsWeakSWF_TieBreak(wp WeakProfile, alts []Alternative, tieBreak []Alternative) ([]Alternative, error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, err
+	}
+	return rankedPairs(pm, tieBreak), nil
+}
+
+func KemenyWeakSWF(wp WeakProfile, alts []Alternative, tieBreak []Alternative) (ranking []Alternative, exact bool, err error) {
+	pm, err := NewWeakPairwiseMatrix(wp, alts)
+	if err != nil {
+		return nil, false, err
+	}
+	ranking, exact = kemeny(pm, tieBreak)
+	return ranking, exact, nil
+}
@@ -0,0 +1,129 @@
+package comsoc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+/*
+* Weighted (anonymous) profiles
+* Large electorates give the same rankings many times: a WeightedProfile stores each
+* ranking once with its weight, i.e. the number of voters giving it (or the sum of their
+* weights when voters are weighted). All the rules are computed on weighted profiles,
+* the functions taking a Profile compress it first
+ */
+
+// Returns a key identifying a ranking
+func rankingKey(ranking []Alternative) string {
+	key := make([]byte, 0, 4*len(ranking))
+	for _, alt := range ranking {
+		key = strconv.AppendInt(key, int64(alt), 10)
+		key = append(key, ',')
+	}
+	return string(key)
+}
+
+// Groups the identical rankings of the profile, in the order of their first appearance.
+// weights gives the weight of each voter (nil if every voter has a weight of 1)
+func NewWeightedProfile(p Profile, weights []int) (WeightedProfile, error) {
+	if weights != nil && len(weights) != len(p) {
+		return WeightedProfile{}, errors.New("weights do not match the voters")
+	}
+	wp := WeightedProfile{}
+	indexes := make(map[string]int)
+	for i, votant := range p {
+		w := 1
+		if weights != nil {
+			w = weights[i]
+		}
+		key := rankingKey(votant)
+		ind, ok := indexes[key]
+		if !ok {
+			ind = len(wp.Rankings)
+			indexes[key] = ind
+			wp.Rankings = append(wp.Rankings, votant)
+			wp.Weights = append(wp.Weights, 0)
+		}
+		wp.Weights[ind] += w
+	}
+	return wp, nil
+}
+
+// Same as NewWeightedProfile with every voter having a weight of 1
+func Compress(p Profile) WeightedProfile {
+	wp, _ := NewWeightedProfile(p, nil)
+	return wp
+}
+
+// Groups the voters giving the same ranking with the same approval threshold.
+// Returns the weighted profile and the threshold of each of its rankings
+func CompressApproval(p Profile, thresholds []int) (WeightedProfile, []int, error) {
+	if len(thresholds) != len(p) {
+		return WeightedProfile{}, nil, fmt.Errorf("%d thresholds given for %d voters", len(thresholds), len(p))
+	}
+	wp := WeightedProfile{}
+	var wThresholds []int
+	indexes := make(map[string]int)
+	for i, votant := range p {
+		key := strconv.Itoa(thresholds[i]) + ":" + rankingKey(votant)
+		ind, ok := indexes[key]
+		if !ok {
+			ind = len(wp.Rankings)
+			indexes[key] = ind
+			wp.Rankings = append(wp.Rankings, votant)
+			wp.Weights = append(wp.Weights, 0)
+			wThresholds = append(wThresholds, thresholds[i])
+		}
+		wp.Weights[ind]++
+	}
+	return wp, wThresholds, nil
+}
+
+// Returns the total weight of the voters
+func (wp WeightedProfile) NbVoters() int {
+	var total int
+	for _, w := range wp.Weights {
+		total += w
+	}
+	return total
+}
+
+// Returns the alternatives of the profile (in the order of the first ranking)
+func (wp WeightedProfile) Alternatives() []Alternative {
+	return wp.Rankings[0]
+}
+
+// Checks the given weighted profile: the rankings must form a correct profile and the weights be positive
+func checkWeightedProfile(wp WeightedProfile) error {
+	if len(wp.Weights) != len(wp.Rankings) {
+		return errors.New("weights do not match the rankings")
+	}
+	for _, w := range wp.Weights {
+		if w < 0 {
+			return errors.New("negative weight")
+		}
+	}
+	err := checkProfile(wp.Rankings)
+	if err != nil {
+		return err
+	}
+	if wp.NbVoters() == 0 {
+		return errors.New("no votes submitted")
+	}
+	return nil
+}
+
+// Returns a copy of the weighted profile without the given alternatives, keeping the order of the others
+func removeAlternatives(wp WeightedProfile, alts ...Alternative) WeightedProfile {
+	res := WeightedProfile{Rankings: make([][]Alternative, len(wp.Rankings)), Weights: wp.Weights}
+	for i, ranking := range wp.Rankings {
+		res.Rankings[i] = make([]Alternative, 0, len(ranking))
+		for _, alt := range ranking {
+			if rank(alt, alts) == -1 {
+				res.Rankings[i] = append(res.Rankings[i], alt)
+			}
+		}
+	}
+	return res
+}
@@ -3,6 +3,8 @@ package endpoints
 const Vote = "/vote"
 const Results = "/result"
 const NewBallot = "/new_ballot"
+const Manipulation = "/manipulation"
+const Import = "/import"
 
 const ServerPort = ":8080"
 const ServerHost = "http://localhost"
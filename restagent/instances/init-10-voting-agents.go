@@ -7,24 +7,65 @@ import (
 
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc/generator"
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/restclientagent"
 )
 
-func generatePrefs(nbAlts int) []comsoc.Alternative {
-	intPref := rand.Perm(nbAlts)
-	altPref := make([]comsoc.Alternative, nbAlts)
-	for i := 0; i < nbAlts; i++ {
-		//conversion en []Alternative
-		altPref[i] = comsoc.Alternative(intPref[i] + 1)
+// Générateur aléatoire des instances, à réinitialiser par SetSeed pour rejouer une simulation
+var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+func SetSeed(seed int64) {
+	rng = rand.New(rand.NewSource(seed))
+}
+
+// Alternatives 1..nbAlts
+func generateAlts(nbAlts int) []comsoc.Alternative {
+	alts := make([]comsoc.Alternative, nbAlts)
+	for i := range alts {
+		alts[i] = comsoc.Alternative(i + 1)
 	}
-	return altPref
+	return alts
+}
+
+// Classement uniforme des alternatives (culture impartiale)
+func generatePrefs(nbAlts int) []comsoc.Alternative {
+	return generator.Ranking(rng, generateAlts(nbAlts))
 }
 
 func generateThresholds(nbAlts int) []int {
-	res := []int{rand.Intn(nbAlts + 1)}
+	res := []int{rng.Intn(nbAlts + 1)}
+	return res
+}
+
+// Note maximale des votes pour les méthodes cardinales (notes de 0 à maxGrade)
+const maxGrade = 5
+
+func generateGrades(nbAlts int) []int {
+	res := make([]int, nbAlts)
+	for i := range res {
+		res[i] = rng.Intn(maxGrade + 1)
+	}
 	return res
 }
 
+// Génère les options nécessaires à certaines méthodes de vote
+func generateBallotOptions(rule string, nbAlts int) restagent.BallotOptions {
+	options := restagent.BallotOptions{}
+	if rule == restagent.Scoring {
+		options.ScoreVector = comsoc.DowdallVector(nbAlts)
+	}
+	if restagent.ContainsRule(restagent.CommitteeRules, rule) {
+		options.Seats = (nbAlts + 1) / 2
+	}
+	if restagent.ContainsRule(restagent.CardinalRules, rule) {
+		options.MaxGrade = maxGrade
+	}
+	if rule == restagent.CondorcetCompletion {
+		options.Fallback = restagent.Schulze
+	}
+	return options
+}
+
 func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVotants []chan []string, listCinBallots []chan []string, cout chan string) ([]restclientagent.RestClientVoteAgent, []restclientagent.RestClientBallotAgent) {
 	listAgentsId := make([]string, n)
 	for i := 0; i < n; i++ {
@@ -38,11 +79,12 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 	for i, rule := range restagent.Rules {
 		ballotAgents[i] = *restclientagent.NewRestClientBallotAgent("ag_scrut_"+rule, url,
 			restagent.RequestNewBallot{
-				Rule:     restagent.Rules[i],
-				Deadline: time.Now().Add(5 * time.Second).Format(time.RFC3339),
-				VoterIds: listAgentsId[:],
-				Alts:     nbAlts,
-				TieBreak: []comsoc.Alternative{1, 2, 3, 4, 5},
+				Rule:          restagent.Rules[i],
+				Deadline:      time.Now().Add(5 * time.Second).Format(time.RFC3339),
+				VoterIds:      listAgentsId[:],
+				Alts:          nbAlts,
+				TieBreak:      []comsoc.Alternative{1, 2, 3, 4, 5},
+				BallotOptions: generateBallotOptions(rule, nbAlts),
 			},
 			listCinBallots[i],
 			cout)
@@ -89,6 +131,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[0],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[0],
 		cout)
@@ -100,6 +143,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[1],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		}, listCinVotants[1], cout,
 	)
 
@@ -109,6 +153,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[2],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[2],
 		cout)
@@ -119,6 +164,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[3],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[3],
 		cout)
@@ -129,6 +175,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[4],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[4],
 		cout)
@@ -139,6 +186,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[5],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[5],
 		cout)
@@ -149,6 +197,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[6],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[6],
 		cout)
@@ -159,6 +208,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[7],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[7],
 		cout)
@@ -169,6 +219,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[8],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[8],
 		cout)
@@ -179,6 +230,7 @@ func Init10VotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVot
 			AgentId: listAgentsId[9],
 			Prefs:   generatePrefs(nbAlts),
 			Options: generateThresholds(nbAlts),
+			Grades:  generateGrades(nbAlts),
 		},
 		listCinVotants[9],
 		cout)
@@ -0,0 +1,52 @@
+package instances
+
+import (
+	"strconv"
+	"time"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/restclientagent"
+)
+
+/**
+* Rejoue un profil (par exemple le profil modifié d'une attaque, voir comsoc.RelabelProfile)
+* Un scrutin de la méthode rule, et un agent votant par classement du profil
+* Utilisation : LaunchAgents(1, len(p), len(p[0]), InitProfileAgents(rule, options, tieBreak, p))
+**/
+func InitProfileAgents(rule string, options restagent.BallotOptions, tieBreak []comsoc.Alternative, p comsoc.Profile) func(url string, n int, nbBallots int, nbAlts int, listCinVotants []chan []string, listCinBallots []chan []string, cout chan string) ([]restclientagent.RestClientVoteAgent, []restclientagent.RestClientBallotAgent) {
+	return func(url string, n int, nbBallots int, nbAlts int, listCinVotants []chan []string, listCinBallots []chan []string, cout chan string) ([]restclientagent.RestClientVoteAgent, []restclientagent.RestClientBallotAgent) {
+		listAgentsId := make([]string, n)
+		for i := 0; i < n; i++ {
+			listAgentsId[i] = "ag_vote_" + strconv.Itoa(i+1)
+		}
+
+		//Création du scrutin
+		reqNewBallot := restagent.RequestNewBallot{
+			Rule:          rule,
+			Deadline:      time.Now().Add(5 * time.Second).Format(time.RFC3339),
+			VoterIds:      listAgentsId[:],
+			Alts:          nbAlts,
+			TieBreak:      tieBreak,
+			BallotOptions: options,
+		}
+		ballotAgent := []restclientagent.RestClientBallotAgent{
+			*restclientagent.NewRestClientBallotAgent("ag_scrut", url, reqNewBallot, listCinBallots[0], cout),
+		}
+
+		//Chaque agent vote pour son classement du profil
+		voteAgents := make([]restclientagent.RestClientVoteAgent, n)
+		for i := 0; i < n; i++ {
+			voteAgents[i] = *restclientagent.NewRestClientVoteAgent(listAgentsId[i], url,
+				restagent.RequestVote{
+					AgentId: listAgentsId[i],
+					Prefs:   p[i],
+					Options: nil,
+				},
+				listCinVotants[i],
+				cout)
+		}
+
+		return voteAgents, ballotAgent
+	}
+}
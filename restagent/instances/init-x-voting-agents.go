@@ -1,50 +1,61 @@
 package instances
 
 import (
-	"math/rand"
 	"strconv"
 	"time"
 
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc/generator"
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/restclientagent"
 )
 
+// Scrutins de méthodes aléatoires, les votants tirant leurs classements selon la culture impartiale
 func InitVotingAgents(url string, n int, nbBallots int, nbAlts int, listCinVotants []chan []string, listCinBallots []chan []string, cout chan string) ([]restclientagent.RestClientVoteAgent, []restclientagent.RestClientBallotAgent) {
-	listAgentsId := make([]string, n)
-	for i := 0; i < n; i++ {
-		listAgentsId[i] = "ag_vote_" + strconv.Itoa(i+1)
-	}
-
-	voteAgents := make([]restclientagent.RestClientVoteAgent, n)
-	ballotAgents := make([]restclientagent.RestClientBallotAgent, nbBallots)
-
-	//Création des scrutins
-
-	for i := 0; i < nbBallots; i++ {
-		ballotAgents[i] = *restclientagent.NewRestClientBallotAgent("ag_scrut_"+strconv.Itoa(i+1), url,
-			restagent.RequestNewBallot{
-				Rule:     restagent.Rules[rand.Intn(len(restagent.Rules))],
-				Deadline: time.Now().Add(5 * time.Second).Format(time.RFC3339),
-				VoterIds: listAgentsId[:],
-				Alts:     nbAlts,
-				TieBreak: generatePrefs(nbAlts),
-			},
-			listCinBallots[i],
-			cout)
-	}
+	return InitGeneratedVotingAgents(generator.ImpartialCulture)(url, n, nbBallots, nbAlts, listCinVotants, listCinBallots, cout)
+}
 
-	//Création des votants
-
-	for i := 0; i < n; i++ {
-		voteAgents[i] = *restclientagent.NewRestClientVoteAgent(listAgentsId[i], url,
-			restagent.RequestVote{
-				AgentId: listAgentsId[i],
-				Prefs:   generatePrefs(nbAlts),
-				Options: generateThresholds(nbAlts),
-			},
-			listCinVotants[i],
-			cout)
+// Scrutins de méthodes aléatoires, le profil des votants étant tiré selon le modèle donné (voir le package generator)
+func InitGeneratedVotingAgents(model generator.Generator) func(url string, n int, nbBallots int, nbAlts int, listCinVotants []chan []string, listCinBallots []chan []string, cout chan string) ([]restclientagent.RestClientVoteAgent, []restclientagent.RestClientBallotAgent) {
+	return func(url string, n int, nbBallots int, nbAlts int, listCinVotants []chan []string, listCinBallots []chan []string, cout chan string) ([]restclientagent.RestClientVoteAgent, []restclientagent.RestClientBallotAgent) {
+		listAgentsId := make([]string, n)
+		for i := 0; i < n; i++ {
+			listAgentsId[i] = "ag_vote_" + strconv.Itoa(i+1)
+		}
+
+		voteAgents := make([]restclientagent.RestClientVoteAgent, n)
+		ballotAgents := make([]restclientagent.RestClientBallotAgent, nbBallots)
+
+		//Création des scrutins
+
+		for i := 0; i < nbBallots; i++ {
+			rule := restagent.Rules[rng.Intn(len(restagent.Rules))]
+			ballotAgents[i] = *restclientagent.NewRestClientBallotAgent("ag_scrut_"+strconv.Itoa(i+1), url,
+				restagent.RequestNewBallot{
+					Rule:          rule,
+					Deadline:      time.Now().Add(5 * time.Second).Format(time.RFC3339),
+					VoterIds:      listAgentsId[:],
+					Alts:          nbAlts,
+					TieBreak:      generatePrefs(nbAlts),
+					BallotOptions: generateBallotOptions(rule, nbAlts),
+				},
+				listCinBallots[i],
+				cout)
+		}
+
+		//Création des votants
+		profile := model(rng, n, generateAlts(nbAlts))
+		for i := 0; i < n; i++ {
+			voteAgents[i] = *restclientagent.NewRestClientVoteAgent(listAgentsId[i], url,
+				restagent.RequestVote{
+					AgentId: listAgentsId[i],
+					Prefs:   profile[i],
+					Options: generateThresholds(nbAlts),
+					Grades:  generateGrades(nbAlts),
+				},
+				listCinVotants[i],
+				cout)
+		}
+
+		return voteAgents, ballotAgents
 	}
-
-	return voteAgents, ballotAgents
 }
@@ -98,26 +98,24 @@ func LaunchAgents(nbBallot int, nbVotant int, nbAlts int, generateAgentsFunc fun
 	for _, ballot := range listBallotAgents {
 		fmt.Printf("\n\nScrutin %v\n", ballot.RestClientAgentBase.Id)
 
-		matrice := make([][]int, nbAlts+1)                             // matrice des préférences des votants
-		listProfiles := make([]restclientagent.RestClientVoteAgent, 0) // liste des profils déjà inclus dans la matrice
-		for i, agent := range listVoteAgents {
-			if canVote(ballot.ReqNewBallot.VoterIds, agent.Id) {
-				k := 1 // nombre de votants ayant le même profil
-				for j, agent2 := range listVoteAgents {
-					// si l'agent j n'est pas l'agent i et que les préférences de l'agent i et de l'agent j sont égales
-					if i != j && areSlicesEqual(agent.ReqVote.Prefs, agent2.ReqVote.Prefs) && canVote(ballot.ReqNewBallot.VoterIds, agent2.Id) {
-						listProfiles = append(listProfiles, agent2) // on ajoute l'agent j à la liste des profils déjà inclus dans la matrice
-						k++                                         // on incrémente le nombre de votants ayant le même profil
-					}
+		// profil des votants autorisés, les préférences identiques étant regroupées
+		voters := make(map[string]bool, len(ballot.ReqNewBallot.VoterIds))
+		for _, id := range ballot.ReqNewBallot.VoterIds {
+			voters[id] = true
+		}
+		profile := make(comsoc.Profile, 0, len(listVoteAgents))
+		for _, agent := range listVoteAgents {
+			if voters[agent.Id] {
+				profile = append(profile, agent.ReqVote.Prefs)
+			}
+		}
+		wp := comsoc.Compress(profile)
 
-				}
-				// si l'agent i n'est pas déjà dans la matrice
-				if notInSlice(agent, listProfiles) {
-					matrice[0] = append(matrice[0], k)
-					for p, alt := range agent.ReqVote.Prefs {
-						matrice[p+1] = append(matrice[p+1], int(alt))
-					}
-				}
+		matrice := make([][]int, nbAlts+1) // matrice des préférences des votants
+		for r, prefs := range wp.Rankings {
+			matrice[0] = append(matrice[0], wp.Weights[r]) // nombre de votants ayant le même profil
+			for p, alt := range prefs {
+				matrice[p+1] = append(matrice[p+1], int(alt))
 			}
 		}
 
@@ -142,36 +140,3 @@ func LaunchAgents(nbBallot int, nbVotant int, nbAlts int, generateAgentsFunc fun
 
 	fmt.Print("\n\n============================= FIN PROGRAMME =============================\n\n")
 }
-
-// Fonction qui compare deux slices d'Alternatives pour déterminer si elles sont égales
-func areSlicesEqual(a, b []comsoc.Alternative) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i, v := range a {
-		if v != b[i] {
-			return false
-		}
-	}
-	return true
-}
-
-// Fonction qui compare un agent avec une liste d'agents pour déterminer si l'agent n'est pas dans la liste
-func notInSlice(a restclientagent.RestClientVoteAgent, b []restclientagent.RestClientVoteAgent) bool {
-	for _, v := range b {
-		if v.ReqVote.AgentId == a.ReqVote.AgentId {
-			return false
-		}
-	}
-	return true
-}
-
-// Fonction qui renvoie True si un agent a le droit de voter à un scrutin
-func canVote(voterIDs []string, agentId string) bool {
-	for _, v := range voterIDs {
-		if v == agentId {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,521 @@
+package restagent
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+/*
+* Registry of the voting rules
+* The server only knows the rules through this registry: a new rule is added by calling
+* RegisterRule (e.g. from a cmd main) before starting the server
+ */
+
+var registry = make(map[string]comsoc.Rule)
+
+// Registers a rule, whose name must not be already used
+func RegisterRule(rule comsoc.Rule) error {
+	if rule.Name() == "" {
+		return errors.New("a rule must have a name")
+	}
+	if _, found := registry[rule.Name()]; found {
+		return fmt.Errorf("rule %s is already registered", rule.Name())
+	}
+	registry[rule.Name()] = rule
+	Rules = append(Rules, rule.Name())
+	return nil
+}
+
+// Returns the registered rule with the given name
+func LookupRule(name string) (comsoc.Rule, bool) {
+	rule, found := registry[name]
+	return rule, found
+}
+
+func init() {
+	builtins := []comsoc.Rule{
+		approvalRule(),
+		positionalRule(scoreRule(Borda, comsoc.RankingFormat, comsoc.PartialAveraged, bordaScores, nil), bordaVector),
+		condorcetFuncRule{condorcetRule()},
+		condorcetScoreRule{scoreRule(Copeland, comsoc.RankingFormat, comsoc.PartialPairwise, copelandScores, checkAlpha, comsoc.OptionAlpha), nil},
+		positionalRule(scoreRule(Majority, comsoc.RankingFormat, comsoc.PartialAveraged, majorityScores, nil), majorityVector),
+		sequentialRule(STV, comsoc.STVExplain),
+		condorcetScoreRule{scoreRule(Schulze, comsoc.RankingFormat, comsoc.PartialPairwise, schulzeScores, nil), nil},
+		condorcetFuncRule{rankedPairsRule()},
+		condorcetFuncRule{kemenyRule()},
+		condorcetScoreRule{scoreRule(Minimax, comsoc.RankingFormat, comsoc.PartialPairwise, minimaxScores, checkVariant, comsoc.OptionVariant), minimaxConsistent},
+		condorcetTraceRule{sequentialRule(Baldwin, comsoc.BaldwinExplain)},
+		condorcetTraceRule{sequentialRule(Nanson, comsoc.NansonExplain)},
+		sequentialRule(Coombs, comsoc.CoombsExplain),
+		sequentialRule(Bucklin, comsoc.BucklinExplain),
+		positionalRule(scoreRule(Scoring, comsoc.RankingFormat, comsoc.PartialAveraged, positionalScores, checkScoreVector, comsoc.OptionScoreVector), optionsVector),
+		multiSTVRule(),
+		optimalCommitteeRule(PAV, comsoc.PAVCommittee),
+		approvalCommitteeRule(SeqPAV, comsoc.SeqPAVCommittee),
+		approvalCommitteeRule(Phragmen, comsoc.PhragmenCommittee),
+		optimalCommitteeRule(ChamberlinCourant, comsoc.ChamberlinCourantCommittee),
+		scoreRule(Range, comsoc.GradeFormat, comsoc.PartialUnsupported, rangeScores, checkMaxGrade, comsoc.OptionMaxGrade),
+		gradeRule(STAR, comsoc.STARSWF_TieBreak),
+		gradeRule(MajorityJudgment, comsoc.MajorityJudgmentSWF_TieBreak),
+		condorcetCompletionRule{Black, Borda},
+		condorcetCompletionRule{CondorcetIRV, STV},
+		condorcetCompletionRule{CondorcetCompletion, ""},
+	}
+	for _, rule := range builtins {
+		err := RegisterRule(rule)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+///// Checks of the options
+
+func checkAlpha(options comsoc.RuleOptions, nbAlts int) error {
+	if options.Alpha != nil && (*options.Alpha < 0 || *options.Alpha > 1) {
+		return errors.New("alpha should be in [0, 1]")
+	}
+	return nil
+}
+
+func checkVariant(options comsoc.RuleOptions, nbAlts int) error {
+	if options.Variant == "" {
+		return nil
+	}
+	for _, v := range comsoc.MinimaxVariants {
+		if v == options.Variant {
+			return nil
+		}
+	}
+	return fmt.Errorf("variant %s is unknown, it should be one of %v", options.Variant, comsoc.MinimaxVariants)
+}
+
+func checkScoreVector(options comsoc.RuleOptions, nbAlts int) error {
+	return comsoc.CheckScoreVector(options.ScoreVector, nbAlts)
+}
+
+func checkSeats(options comsoc.RuleOptions, nbAlts int) error {
+	if options.Seats < 1 || options.Seats > nbAlts {
+		return fmt.Errorf("%d seats should be in [1, %d]", options.Seats, nbAlts)
+	}
+	return nil
+}
+
+func checkMaxGrade(options comsoc.RuleOptions, nbAlts int) error {
+	if options.MaxGrade < 1 {
+		return fmt.Errorf("max grade %d should be >= 1", options.MaxGrade)
+	}
+	return nil
+}
+
+///// Rules giving scores
+
+// Rule ranking the alternatives by decreasing score, ties being broken afterwards by the tie-break
+func scoreRule(name string, format comsoc.BallotFormat, partial comsoc.PartialPolicy, scores func(comsoc.Votes, comsoc.RuleOptions) (comsoc.FloatCount, error), check func(comsoc.RuleOptions, int) error, options ...string) comsoc.ScoreRule {
+	return comsoc.ScoreRule{
+		RuleName:     name,
+		BallotFormat: format,
+		Options:      options,
+		Partial:      partial,
+		CheckFunc:    check,
+		ScoreFunc:    scores,
+	}
+}
+
+// Rule giving scores whose margin of victory is computed exactly
+type marginRule struct {
+	comsoc.ScoreRule
+	margin func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.Margin, error)
+}
+
+// Note: the margin is only computed on complete rankings, not on partial votes
+func (r marginRule) Margin(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.Margin, error) {
+	if options.AllowPartial {
+		return comsoc.Margin{}, errors.New("the margin of victory is only computed on complete rankings")
+	}
+	return r.margin(votes, options, tieBreak)
+}
+
+// Positional rule, vector giving its score vector for the options and the number of alternatives
+func positionalRule(rule comsoc.ScoreRule, vector func(options comsoc.RuleOptions, nbAlts int) []float64) marginRule {
+	return marginRule{rule, func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.Margin, error) {
+		return comsoc.PositionalMargin(comsoc.Compress(votes.Profile), vector(options, len(votes.Alts)), tieBreak)
+	}}
+}
+
+func bordaVector(options comsoc.RuleOptions, nbAlts int) []float64 {
+	return comsoc.BordaVector(nbAlts)
+}
+
+func majorityVector(options comsoc.RuleOptions, nbAlts int) []float64 {
+	return comsoc.KApprovalVector(nbAlts, 1)
+}
+
+func optionsVector(options comsoc.RuleOptions, nbAlts int) []float64 {
+	return options.ScoreVector
+}
+
+// Converts the result of a SWF giving integer scores
+func floatScores(count comsoc.Count, err error) (comsoc.FloatCount, error) {
+	if err != nil {
+		return nil, err
+	}
+	return comsoc.ToFloatCount(count), nil
+}
+
+func approvalRule() marginRule {
+	scores := func(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+		return floatScores(comsoc.ApprovalSWF(votes.Profile, votes.Thresholds))
+	}
+	return marginRule{scoreRule(Approval, comsoc.ApprovalFormat, comsoc.PartialUnsupported, scores, nil), func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.Margin, error) {
+		return comsoc.ApprovalMargin(votes.Profile, votes.Thresholds, tieBreak)
+	}}
+}
+
+func bordaScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	if options.AllowPartial {
+		return comsoc.BordaWeakSWF(votes.Weak, votes.Alts)
+	}
+	return floatScores(comsoc.BordaSWF(votes.Profile))
+}
+
+func majorityScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	if options.AllowPartial {
+		return comsoc.MajorityWeakSWF(votes.Weak, votes.Alts)
+	}
+	return floatScores(comsoc.MajoritySWF(votes.Profile))
+}
+
+func positionalScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	if options.AllowPartial {
+		return comsoc.PositionalWeakSWF(options.ScoreVector)(votes.Weak, votes.Alts)
+	}
+	return comsoc.PositionalSWF(options.ScoreVector)(votes.Profile)
+}
+
+// Note: Tie-break is applied for Copeland only after SWF calculation, not within the process
+func copelandScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	switch {
+	case options.AllowPartial && options.Alpha != nil:
+		return comsoc.CopelandAlphaWeakSWF(votes.Weak, votes.Alts, *options.Alpha)
+	case options.AllowPartial:
+		return floatScores(comsoc.CopelandWeakSWF(votes.Weak, votes.Alts))
+	case options.Alpha != nil:
+		return comsoc.CopelandAlphaSWF(votes.Profile, *options.Alpha)
+	}
+	return floatScores(comsoc.CopelandSWF(votes.Profile))
+}
+
+// Note: unlike Condorcet, Schulze always gives a complete ranking, ties are broken afterwards
+func schulzeScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	if options.AllowPartial {
+		return floatScores(comsoc.SchulzeWeakSWF(votes.Weak, votes.Alts))
+	}
+	return floatScores(comsoc.SchulzeSWF(votes.Profile))
+}
+
+// The default variant of Minimax is winning votes
+func minimaxScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	variant := options.Variant
+	if variant == "" {
+		variant = comsoc.MinimaxWinningVotes
+	}
+	if options.AllowPartial {
+		return floatScores(comsoc.MinimaxWeakSWF(votes.Weak, votes.Alts, variant))
+	}
+	return floatScores(comsoc.MinimaxSWF(votes.Profile, variant))
+}
+
+func rangeScores(votes comsoc.Votes, options comsoc.RuleOptions) (comsoc.FloatCount, error) {
+	return floatScores(comsoc.RangeSWF(votes.Grades))
+}
+
+///// Condorcet-consistent rules (used by the lower bound of the margin of victory)
+
+// Rules electing the Condorcet winner when there is one, whatever their options
+type condorcetFuncRule struct {
+	comsoc.FuncRule
+}
+
+func (r condorcetFuncRule) CondorcetConsistent(options comsoc.RuleOptions) bool {
+	return true
+}
+
+type condorcetTraceRule struct {
+	comsoc.TraceRule
+}
+
+func (r condorcetTraceRule) CondorcetConsistent(options comsoc.RuleOptions) bool {
+	return true
+}
+
+// Rule giving scores which elects the Condorcet winner for the options accepted by consistent
+// (for all the options if consistent is nil)
+type condorcetScoreRule struct {
+	comsoc.ScoreRule
+	consistent func(options comsoc.RuleOptions) bool
+}
+
+func (r condorcetScoreRule) CondorcetConsistent(options comsoc.RuleOptions) bool {
+	return r.consistent == nil || r.consistent(options)
+}
+
+// The pairwise-opposition variant of Minimax is not Condorcet-consistent
+func minimaxConsistent(options comsoc.RuleOptions) bool {
+	return options.Variant != comsoc.MinimaxPairwiseOpposition
+}
+
+///// Rules using the tie-break within the algorithm
+
+// Sequential rule on complete rankings, using the tie-break within its rounds, which explain its result
+func sequentialRule(name string, swf func(comsoc.Profile, []comsoc.Alternative) ([]comsoc.Alternative, comsoc.Explanation, error)) comsoc.TraceRule {
+	return comsoc.TraceRule{
+		RuleName:     name,
+		BallotFormat: comsoc.RankingFormat,
+		TraceFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, comsoc.Explanation, error) {
+			ranking, explanation, err := swf(votes.Profile, tieBreak)
+			if err != nil {
+				return comsoc.RuleResult{}, nil, err
+			}
+			res, err := comsoc.RankingResult(ranking)
+			return res, explanation, err
+		},
+	}
+}
+
+// Cardinal rule, using the tie-break within the algorithm itself
+func gradeRule(name string, swf func(comsoc.GradeProfile, []comsoc.Alternative) ([]comsoc.Alternative, error)) comsoc.FuncRule {
+	return comsoc.FuncRule{
+		RuleName:     name,
+		BallotFormat: comsoc.GradeFormat,
+		Options:      []string{comsoc.OptionMaxGrade},
+		CheckFunc:    checkMaxGrade,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			ranking, err := swf(votes.Grades, tieBreak)
+			if err != nil {
+				return comsoc.RuleResult{}, err
+			}
+			return comsoc.RankingResult(ranking)
+		},
+	}
+}
+
+func rankedPairsRule() comsoc.FuncRule {
+	return comsoc.FuncRule{
+		RuleName:     RankedPairs,
+		BallotFormat: comsoc.RankingFormat,
+		Partial:      comsoc.PartialPairwise,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			var ranking []comsoc.Alternative
+			var err error
+			if options.AllowPartial {
+				ranking, err = comsoc.RankedPairsWeakSWF_TieBreak(votes.Weak, votes.Alts, tieBreak)
+			} else {
+				ranking, err = comsoc.RankedPairsSWF_TieBreak(votes.Profile, tieBreak)
+			}
+			if err != nil {
+				return comsoc.RuleResult{}, err
+			}
+			return comsoc.RankingResult(ranking)
+		},
+	}
+}
+
+// Kemeny is solved exactly only for small numbers of alternatives, the result gives the solver used
+func kemenyRule() comsoc.FuncRule {
+	return comsoc.FuncRule{
+		RuleName:     Kemeny,
+		BallotFormat: comsoc.RankingFormat,
+		Partial:      comsoc.PartialPairwise,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			var ranking []comsoc.Alternative
+			var exact bool
+			var err error
+			if options.AllowPartial {
+				ranking, exact, err = comsoc.KemenyWeakSWF(votes.Weak, votes.Alts, tieBreak)
+			} else {
+				ranking, exact, err = comsoc.KemenySWF(votes.Profile, tieBreak)
+			}
+			if err != nil {
+				return comsoc.RuleResult{}, err
+			}
+			res, err := comsoc.RankingResult(ranking)
+			if exact {
+				res.Solver = "exact"
+			} else {
+				res.Solver = "heuristic"
+			}
+			return res, err
+		},
+	}
+}
+
+// Note: Tie-break is not used for Condorcet. Either a winner or none (winner 0) is returned, without ranking
+func condorcetRule() comsoc.FuncRule {
+	return comsoc.FuncRule{
+		RuleName:     Condorcet,
+		BallotFormat: comsoc.RankingFormat,
+		Partial:      comsoc.PartialPairwise,
+		Result:       comsoc.ResultWinner,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			var bestAlts []comsoc.Alternative
+			var err error
+			if options.AllowPartial {
+				bestAlts, err = comsoc.CondorcetWinnerWeak(votes.Weak, votes.Alts)
+			} else {
+				bestAlts, err = comsoc.CondorcetWinner(votes.Profile)
+			}
+			if err != nil || len(bestAlts) == 0 {
+				return comsoc.RuleResult{}, err
+			}
+			return comsoc.RuleResult{Winner: bestAlts[0]}, nil
+		},
+	}
+}
+
+///// Multi-winner rules, electing a committee rather than a ranking
+
+// The rounds of multi-winner STV are given in the result, and explain it
+func multiSTVRule() comsoc.TraceRule {
+	return comsoc.TraceRule{
+		RuleName:     MultiSTV,
+		BallotFormat: comsoc.RankingFormat,
+		Options:      []string{comsoc.OptionSeats},
+		Result:       comsoc.ResultCommittee,
+		CheckFunc:    checkSeats,
+		TraceFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, comsoc.Explanation, error) {
+			committee, rounds, err := comsoc.STVCommittee(votes.Profile, options.Seats, tieBreak)
+			if err != nil {
+				return comsoc.RuleResult{}, nil, err
+			}
+			return comsoc.RuleResult{Winner: committee[0], Committee: committee, Rounds: rounds}, comsoc.STVCommitteeExplanation(rounds), nil
+		},
+	}
+}
+
+func approvalCommitteeRule(name string, rule func(comsoc.Profile, []int, int, []comsoc.Alternative) ([]comsoc.Alternative, error)) comsoc.FuncRule {
+	return comsoc.FuncRule{
+		RuleName:     name,
+		BallotFormat: comsoc.ApprovalFormat,
+		Options:      []string{comsoc.OptionSeats},
+		Result:       comsoc.ResultCommittee,
+		CheckFunc:    checkSeats,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			committee, err := rule(votes.Profile, votes.Thresholds, options.Seats, tieBreak)
+			if err != nil {
+				return comsoc.RuleResult{}, err
+			}
+			return comsoc.RuleResult{Winner: committee[0], Committee: committee}, nil
+		},
+	}
+}
+
+// PAV and Chamberlin-Courant are solved exactly only for small numbers of committees, the result gives the solver used
+func optimalCommitteeRule(name string, rule func(comsoc.Profile, []int, int, []comsoc.Alternative) ([]comsoc.Alternative, bool, error)) comsoc.FuncRule {
+	return comsoc.FuncRule{
+		RuleName:     name,
+		BallotFormat: comsoc.ApprovalFormat,
+		Options:      []string{comsoc.OptionSeats},
+		Result:       comsoc.ResultCommittee,
+		CheckFunc:    checkSeats,
+		ComputeFunc: func(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+			committee, exact, err := rule(votes.Profile, votes.Thresholds, options.Seats, tieBreak)
+			if err != nil {
+				return comsoc.RuleResult{}, err
+			}
+			res := comsoc.RuleResult{Winner: committee[0], Committee: committee, Solver: "heuristic"}
+			if exact {
+				res.Solver = "exact"
+			}
+			return res, nil
+		},
+	}
+}
+
+///// Condorcet-consistent completion rules
+
+// Elects the Condorcet winner if it exists, otherwise uses the fallback rule
+// (given by the fallback option of the ballot if fallback is empty)
+type condorcetCompletionRule struct {
+	name     string
+	fallback string
+}
+
+func (r condorcetCompletionRule) Name() string {
+	return r.name
+}
+
+func (r condorcetCompletionRule) Format() comsoc.BallotFormat {
+	return comsoc.RankingFormat
+}
+
+func (r condorcetCompletionRule) CondorcetConsistent(options comsoc.RuleOptions) bool {
+	return true
+}
+
+// Returns the fallback rule and the options to give it
+func (r condorcetCompletionRule) fallbackRule(options comsoc.RuleOptions) (comsoc.Rule, comsoc.RuleOptions, error) {
+	name := r.fallback
+	if name == "" {
+		name = options.Fallback
+	}
+	if name == "" {
+		return nil, options, fmt.Errorf("option %s is required for rule %s", comsoc.OptionFallback, r.name)
+	}
+	fallback, found := LookupRule(name)
+	if !found {
+		return nil, options, fmt.Errorf("fallback %s is not a registered rule", name)
+	}
+	// The fallback must give a ranking from complete rankings
+	if _, completion := fallback.(condorcetCompletionRule); completion || comsoc.RuleResultKind(fallback) != comsoc.ResultRanking || fallback.Format() != comsoc.RankingFormat {
+		return nil, options, fmt.Errorf("rule %s cannot be used as a fallback", name)
+	}
+	options.Fallback = ""
+	return fallback, options, nil
+}
+
+// The options are those of the fallback rule
+func (r condorcetCompletionRule) CheckOptions(options comsoc.RuleOptions, nbAlts int) error {
+	if r.fallback != "" && options.Fallback != "" {
+		return fmt.Errorf("option %s is not available for rule %s", comsoc.OptionFallback, r.name)
+	}
+	if options.AllowPartial {
+		return fmt.Errorf("option %s is not available for rule %s", comsoc.OptionAllowPartial, r.name)
+	}
+	fallback, fallbackOptions, err := r.fallbackRule(options)
+	if err != nil {
+		return err
+	}
+	return fallback.CheckOptions(fallbackOptions, nbAlts)
+}
+
+func (r condorcetCompletionRule) Compute(votes comsoc.Votes, options comsoc.RuleOptions, tieBreak []comsoc.Alternative) (comsoc.RuleResult, error) {
+	fallback, fallbackOptions, err := r.fallbackRule(options)
+	if err != nil {
+		return comsoc.RuleResult{}, err
+	}
+	// The result of the fallback (e.g. its solver) is only kept if it decides
+	var res comsoc.RuleResult
+	ranking, condorcet, err := comsoc.CondorcetCompletion(votes.Profile, func(comsoc.Profile) ([]comsoc.Alternative, error) {
+		res, err = fallback.Compute(votes, fallbackOptions, tieBreak)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Ranking) == 0 {
+			return nil, fmt.Errorf("fallback %s gives no ranking", fallback.Name())
+		}
+		return res.Ranking, nil
+	}, tieBreak)
+	if err != nil {
+		return comsoc.RuleResult{}, err
+	}
+	res.Winner, res.Ranking = ranking[0], ranking
+	if condorcet {
+		res.DecidedBy = Condorcet
+	} else {
+		res.DecidedBy = fallback.Name()
+	}
+	return res, nil
+}
@@ -89,11 +89,39 @@ func (rcva *RestClientVoteAgent) Start() {
 
 // Display results
 func Affichage(id string, rule string, nbVoters int, res restagent.ResponseResult) {
-	if rule != "condorcet" {
+	if res.Committee != nil {
+		fmt.Printf("=============================== RESULTS FOR BALLOT %s ===============================\nBALLOT TYPE: %s\nNUMBER OF VOTERS: %d\nWINNER: %d\nCOMMITTEE: %v\n",
+			id, rule, nbVoters, res.Winner, res.Committee)
+	} else if res.TiedGroups != nil {
+		fmt.Printf("=============================== RESULTS FOR BALLOT %s ===============================\nBALLOT TYPE: %s\nNUMBER OF VOTERS: %d\nWINNER: %d\nTIED GROUPS: %v\n",
+			id, rule, nbVoters, res.Winner, res.TiedGroups)
+	} else if rule != "condorcet" {
 		fmt.Printf("=============================== RESULTS FOR BALLOT %s ===============================\nBALLOT TYPE: %s\nNUMBER OF VOTERS: %d\nWINNER: %d\nRANKING: %v\n",
 			id, rule, nbVoters, res.Winner, res.Ranking)
 	} else {
 		fmt.Printf("=============================== RESULTS FOR BALLOT %s ===============================\nBALLOT TYPE: %s\nNUMBER OF VOTERS: %d\nWINNER: %d\n",
 			id, rule, nbVoters, res.Winner)
 	}
+	if res.DecidedBy != "" {
+		fmt.Printf("DECIDED BY: %s\n", res.DecidedBy)
+	}
+	if res.Scores != nil {
+		fmt.Printf("SCORES: %v\nBROKEN TIES: %v\n", res.Scores, res.BrokenTies)
+	}
+	if res.TieBreak != nil {
+		fmt.Printf("TIE-BREAK: %v\n", res.TieBreak)
+	}
+	if res.Seed != nil {
+		fmt.Printf("SEED: %d\n", *res.Seed)
+	}
+	if res.Margin != nil {
+		fmt.Printf("MARGIN OF VICTORY: %d", res.Margin.Lower)
+		if !res.Margin.Exact {
+			fmt.Printf(" to %d", res.Margin.Upper)
+		}
+		fmt.Printf("\n")
+	}
+	for _, round := range res.Explanation {
+		fmt.Printf("ROUND %d (%s): %v, elected %v, eliminated %v, tie-break used: %t\n", round.Round, round.Criterion, round.Tallies, round.Elected, round.Eliminated, round.TieBreakUsed)
+	}
 }
@@ -0,0 +1,190 @@
+package restserveragent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc/preflib"
+)
+
+// Functions that handle the call to the REST API to import the votes of a PrefLib file into an open ballot:
+// http://localhost:8080/import
+
+// Decode the request
+func (*RestServerAgent) decodeImportRequest(r *http.Request) (req restagent.RequestImport, err error) {
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(r.Body)
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		fmt.Println("Error decoding request /import: ", err)
+		return
+	}
+	return
+}
+
+// Check the consistency of the request, all the votes being checked before any of them is registered
+// (data is only read if the ballot exists)
+func checkImportRequest(ballotsList map[string]restagent.Ballot, req restagent.RequestImport, data *preflib.Data) (err error) {
+	// Check if the ballot exists
+	ballot, found := ballotsList[req.BallotId]
+	if !found {
+		return fmt.Errorf("notexist")
+	}
+	// Check if the deadline has passed
+	if ballot.Deadline.Before(time.Now()) {
+		return fmt.Errorf("alreadyfinished")
+	}
+	// Check the voters: one per vote, allowed and not having voted yet
+	if len(req.VoterIds) != len(data.Votes) {
+		return fmt.Errorf("wrongvoters")
+	}
+	importing := make(map[string]bool, len(req.VoterIds))
+	for _, id := range req.VoterIds {
+		if importing[id] {
+			return fmt.Errorf("wrongvoters")
+		}
+		importing[id] = true
+	}
+	for _, v := range ballot.HaveVoted {
+		if importing[v] {
+			return fmt.Errorf("alreadyvoted")
+		}
+	}
+	var nbAllowed int
+	for _, v := range ballot.VoterIds {
+		if importing[v] {
+			nbAllowed++
+		}
+	}
+	if nbAllowed != len(req.VoterIds) {
+		return fmt.Errorf("notallowed")
+	}
+
+	// Check if the votes match the ballot
+	if data.NbAlts != ballot.Alts {
+		return fmt.Errorf("wrongalts")
+	}
+	switch ballotFormat(ballot) {
+	case comsoc.GradeFormat:
+		return fmt.Errorf("wrongformat")
+	case comsoc.ApprovalFormat:
+		if _, _, err := data.Approval(); err != nil {
+			return fmt.Errorf("wrongformat")
+		}
+	default:
+		if !ballot.AllowPartial && data.InferType() != preflib.SOC {
+			return fmt.Errorf("notcomplete")
+		}
+	}
+	return nil
+}
+
+// Registers the votes of a PrefLib file for a list of voters of an open ballot
+func (rsa *RestServerAgent) doImport(w http.ResponseWriter, r *http.Request) {
+	rsa.Lock()
+	defer rsa.Unlock()
+	// Check the request method
+	if !rsa.checkMethod("POST", w, r) {
+		return
+	}
+
+	req, err := rsa.decodeImportRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		fmt.Fprint(w, err.Error())
+		return
+	}
+	// The file is read once the ballot is known, as it can't have more votes than the voters of the ballot
+	var data *preflib.Data
+	if ballot, found := rsa.ballotsList[req.BallotId]; found {
+		data, err = preflib.ReadLimit(strings.NewReader(req.Data), len(ballot.VoterIds))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /import: the data for ballot %s is not a correct PrefLib file. "+err.Error(), req.BallotId)
+			w.Write([]byte(msg))
+			return
+		}
+	}
+
+	// Check request
+	err = checkImportRequest(rsa.ballotsList, req, data)
+	if err != nil {
+		switch err.Error() {
+		case "notexist":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /import: ballot %s does not exist", req.BallotId)
+			w.Write([]byte(msg))
+			return
+		case "alreadyfinished":
+			w.WriteHeader(http.StatusServiceUnavailable) // 503
+			msg := fmt.Sprintf("error /import: ballot %s is already finished: %s", req.BallotId, rsa.ballotsList[req.BallotId].Deadline.String())
+			w.Write([]byte(msg))
+			return
+		case "wrongvoters":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /import: %d voter ids given, %d distinct ones are expected (one per vote of the file)", len(req.VoterIds), len(data.Votes))
+			w.Write([]byte(msg))
+			return
+		case "alreadyvoted":
+			w.WriteHeader(http.StatusForbidden) // 403
+			msg := fmt.Sprintf("error /import: some of the agents have already voted for ballot %s", req.BallotId)
+			w.Write([]byte(msg))
+			return
+		case "notallowed":
+			w.WriteHeader(http.StatusUnauthorized) // 401
+			msg := fmt.Sprintf("error /import: some of the agents are not allowed to vote for ballot %s", req.BallotId)
+			w.Write([]byte(msg))
+			return
+		case "wrongalts":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /import: the file has %d alternatives, ballot %s has %d", data.NbAlts, req.BallotId, rsa.ballotsList[req.BallotId].Alts)
+			w.Write([]byte(msg))
+			return
+		case "wrongformat":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /import: votes of type %s can't be imported into ballot %s of type %s", data.Type, req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			w.Write([]byte(msg))
+			return
+		case "notcomplete":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /import: ballot %s does not allow partial votes, complete strict orders (soc) are expected", req.BallotId)
+			w.Write([]byte(msg))
+			return
+		}
+	}
+
+	// Save the votes (and the thresholds) for the ballot
+	ballot := rsa.ballotsList[req.BallotId]
+	switch {
+	case ballotFormat(ballot) == comsoc.ApprovalFormat:
+		profile, thresholds, _ := data.Approval()
+		for i, id := range req.VoterIds {
+			ballot.Thresholds[id] = thresholds[i]
+		}
+		rsa.ballotsMap[req.BallotId] = append(rsa.ballotsMap[req.BallotId], profile...)
+	case ballot.AllowPartial:
+		rsa.weakMap[req.BallotId] = append(rsa.weakMap[req.BallotId], data.Votes...)
+	default:
+		profile, _ := data.Profile()
+		rsa.ballotsMap[req.BallotId] = append(rsa.ballotsMap[req.BallotId], profile...)
+	}
+
+	// Record that the agents have voted, in the order of the votes
+	var next int
+	for i := 0; i < len(ballot.HaveVoted) && next < len(req.VoterIds); i++ {
+		if ballot.HaveVoted[i] == "" {
+			ballot.HaveVoted[i] = req.VoterIds[next]
+			next++
+		}
+	}
+
+	w.WriteHeader(http.StatusOK) // 200
+	msg := fmt.Sprintf("/import: %d votes registered", len(req.VoterIds))
+	w.Write([]byte(msg))
+}
@@ -0,0 +1,146 @@
+package restserveragent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
+	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent/comsoc"
+)
+
+// Functions that handle the call to the REST API to analyse the manipulability of a closed ballot:
+// http://localhost:8080/manipulation
+
+// Decode the request
+func (*RestServerAgent) decodeManipulationRequest(r *http.Request) (req restagent.RequestManipulation, err error) {
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(r.Body)
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		fmt.Println("Error decoding request /manipulation: ", err)
+		return
+	}
+	return
+}
+
+// Check the consistency of the request, nbVotes being the number of votes of the ballot
+func checkManipulationRequest(ballotsList map[string]restagent.Ballot, req restagent.RequestManipulation, nbVotes int) (err error) {
+	// Check if the ballot exists
+	ballot, found := ballotsList[req.BallotId]
+	if !found {
+		return fmt.Errorf("notexist")
+	}
+	// Only closed ballots are analysed, so that the analysis does not help the voters
+	if ballot.Deadline.After(time.Now()) {
+		return fmt.Errorf("notfinished")
+	}
+	// The number of coalitions grows quickly with their size, so it is bounded
+	if req.CoalitionSize < 0 || req.CoalitionSize > comsoc.MaxCoalitionSize || (req.CoalitionSize > 1 && req.CoalitionSize > nbVotes) {
+		return fmt.Errorf("coalitionsize")
+	}
+	// The manipulators report other complete rankings, and there must be a winner to manipulate
+	rule, _ := restagent.LookupRule(ballot.Rule)
+	if rule.Format() != comsoc.RankingFormat || ballot.AllowPartial || ballot.Seats > 0 || ballot.Strategy == comsoc.TieBreakReport {
+		return fmt.Errorf("noranking")
+	}
+	return
+}
+
+// Searches a coalition of voters of a closed ballot able to obtain a winner they all prefer
+func (rsa *RestServerAgent) doManipulation(w http.ResponseWriter, r *http.Request) {
+	rsa.Lock()
+	defer rsa.Unlock()
+	// Check the request method
+	if !rsa.checkMethod("POST", w, r) {
+		return
+	}
+
+	req, err := rsa.decodeManipulationRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		fmt.Fprint(w, err.Error())
+		return
+	}
+
+	// Check request
+	err = checkManipulationRequest(rsa.ballotsList, req, len(rsa.ballotsMap[req.BallotId]))
+	if err != nil {
+		switch err.Error() {
+		case "notexist":
+			w.WriteHeader(http.StatusNotFound) // 404
+			msg := fmt.Sprintf("error /manipulation: ballot %s does not exist", req.BallotId)
+			w.Write([]byte(msg))
+			return
+		case "notfinished":
+			w.WriteHeader(http.StatusTooEarly) // 425
+			msg := fmt.Sprintf("error /manipulation: ballot %s is not finished yet. Deadline: %s", req.BallotId, rsa.ballotsList[req.BallotId].Deadline)
+			w.Write([]byte(msg))
+			return
+		case "coalitionsize":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /manipulation: coalition size %d should be in [1, %d] and at most the number of votes", req.CoalitionSize, comsoc.MaxCoalitionSize)
+			w.Write([]byte(msg))
+			return
+		case "noranking":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /manipulation: ballot %s of type %s does not elect a single winner from complete rankings", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			w.Write([]byte(msg))
+			return
+		}
+	}
+	if req.CoalitionSize == 0 {
+		req.CoalitionSize = 1
+	}
+
+	ballot := rsa.ballotsList[req.BallotId]
+	rule, _ := restagent.LookupRule(ballot.Rule)
+	alts := ballotAlternatives(ballot)
+	// The tie-break is computed again from the reported votes, as some strategies depend on them
+	winner := func(p comsoc.Profile) (comsoc.Alternative, error) {
+		votes := comsoc.Votes{Alts: alts, Profile: p}
+		tieBreak, err := ballotTieBreak(ballot, votes)
+		if err != nil {
+			return 0, err
+		}
+		res, err := rule.Compute(votes, ballot.BallotOptions, tieBreak)
+		return res.Winner, err
+	}
+
+	resp := restagent.ResponseManipulation{Exhaustive: true}
+	profile := rsa.ballotsMap[req.BallotId]
+	// Without votes, nobody can manipulate
+	if len(profile) > 0 {
+		resp.SincereWinner, err = winner(profile)
+		if err == nil {
+			var manipulation *comsoc.Manipulation
+			manipulation, resp.Exhaustive, err = comsoc.FindManipulation(profile, winner, req.CoalitionSize)
+			if manipulation != nil {
+				resp.Manipulable = true
+				resp.Winner = manipulation.Winner
+				resp.Ballots = manipulation.Ballots
+				for _, v := range manipulation.Voters {
+					resp.Voters = append(resp.Voters, ballot.HaveVoted[v]) // the votes are stored in the order of HaveVoted
+				}
+			}
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError) // 500
+			msg := fmt.Sprintf("error /manipulation: can't analyse ballot %s of type %s. "+err.Error(), req.BallotId, ballot.Rule)
+			w.Write([]byte(msg))
+			return
+		}
+	}
+
+	serial, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError) // 500
+		msg := fmt.Sprintf("error /manipulation: can't serialize response for ballot %s of type %s", req.BallotId, ballot.Rule)
+		w.Write([]byte(msg))
+		return
+	}
+	w.WriteHeader(http.StatusOK) // 200
+	w.Write(serial)
+}
@@ -35,15 +35,9 @@ func checkBallot(req restagent.RequestNewBallot) (err error) {
 		return fmt.Errorf("deadline")
 	}
 
-	// Check that the type of ballot is allowed
-	var authorized = false
-	for _, v := range restagent.Rules {
-		if v == req.Rule {
-			authorized = true
-			break
-		}
-	}
-	if !authorized {
+	// Check that the type of ballot is allowed (i.e., registered)
+	rule, found := restagent.LookupRule(req.Rule)
+	if !found {
 		return fmt.Errorf("rule")
 	}
 
@@ -54,7 +48,8 @@ func checkBallot(req restagent.RequestNewBallot) (err error) {
 
 	// Check that the tie-break is consistent with the alternatives
 	// Note: since the Tie-break is not used for Condorcet, we do not check if it is consistent
-	if req.Rule != restagent.Condorcet {
+	// It is optional for the strategies which do not use it (random and report), but checked if given
+	if req.Rule != restagent.Condorcet && (req.TieBreak != nil || (req.Strategy != comsoc.TieBreakRandom && req.Strategy != comsoc.TieBreakReport)) {
 		if req.TieBreak == nil || len(req.TieBreak) != req.Alts {
 			return fmt.Errorf("tiebreak")
 		} else {
@@ -70,7 +65,61 @@ func checkBallot(req restagent.RequestNewBallot) (err error) {
 		}
 	}
 
-	return
+	// Check that the rule declares how it treats partial ballots if the ballot allows them
+	if req.AllowPartial && comsoc.RulePartialPolicy(rule) == comsoc.PartialUnsupported {
+		return fmt.Errorf("rule %s does not accept partial ballots (option %s)", rule.Name(), comsoc.OptionAllowPartial)
+	}
+
+	// Check the options specific to the voting method
+	// Note: the error of the rule is returned as is, to be displayed to the client
+	err = rule.CheckOptions(req.BallotOptions, req.Alts)
+	if err != nil {
+		return err
+	}
+	return checkTieBreakOptions(req, rule)
+}
+
+// Options given to the second-order rule of a ballot: only those describing the votes
+func secondOrderOptions(options restagent.BallotOptions) restagent.BallotOptions {
+	return restagent.BallotOptions{AllowPartial: options.AllowPartial, MaxGrade: options.MaxGrade}
+}
+
+// Check the tie-breaking strategy of the ballot
+func checkTieBreakOptions(req restagent.RequestNewBallot, rule comsoc.Rule) error {
+	// The strategies based on the votes complete or break ties with the tie-break given at ballot creation,
+	// which is not required for Condorcet (as it ignores it)
+	if rule.Name() == restagent.Condorcet && (req.Strategy == comsoc.TieBreakFirstBallot || req.Strategy == comsoc.TieBreakRandomBallot || req.Strategy == comsoc.TieBreakRule) {
+		return fmt.Errorf("rule %s does not use the tie-break, so it cannot use tie-break strategy %s", rule.Name(), req.Strategy)
+	}
+	switch req.Strategy {
+	case "", comsoc.TieBreakFixed, comsoc.TieBreakRandom, comsoc.TieBreakFirstBallot, comsoc.TieBreakRandomBallot:
+	case comsoc.TieBreakRule:
+		// The second-order rule is computed on the same votes, with the tie-break given at ballot creation
+		second, found := restagent.LookupRule(req.SecondOrderRule)
+		if !found {
+			return fmt.Errorf("second-order rule %s is not a registered rule", req.SecondOrderRule)
+		}
+		if second.Name() == rule.Name() || comsoc.RuleResultKind(second) != comsoc.ResultRanking || second.Format() != rule.Format() {
+			return fmt.Errorf("rule %s cannot break the ties of rule %s", second.Name(), rule.Name())
+		}
+		err := second.CheckOptions(secondOrderOptions(req.BallotOptions), req.Alts)
+		if err != nil {
+			return err
+		}
+	case comsoc.TieBreakReport:
+		if _, ok := rule.(comsoc.Scorer); !ok {
+			return fmt.Errorf("rule %s gives no scores, so it cannot report tied groups", rule.Name())
+		}
+	default:
+		return fmt.Errorf("tie-break strategy %s is unknown, it should be one of %v", req.Strategy, comsoc.TieBreakStrategies)
+	}
+	if req.Seed != nil && !req.UsesSeed() {
+		return fmt.Errorf("seed is only available for tie-break strategies %s and %s", comsoc.TieBreakRandom, comsoc.TieBreakRandomBallot)
+	}
+	if req.SecondOrderRule != "" && req.Strategy != comsoc.TieBreakRule {
+		return fmt.Errorf("second-order rule is only available for tie-break strategy %s", comsoc.TieBreakRule)
+	}
+	return nil
 }
 
 func (rsa *RestServerAgent) doCreateNewBallot(w http.ResponseWriter, r *http.Request) {
@@ -113,13 +162,24 @@ func (rsa *RestServerAgent) doCreateNewBallot(w http.ResponseWriter, r *http.Req
 			msg := fmt.Sprintf("error /new_ballot: given tie-break %d is invalid or doesn't match #alts %d", req.TieBreak, req.Alts)
 			w.Write([]byte(msg))
 			return
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			msg := fmt.Sprintf("error /new_ballot: %s", err.Error())
+			w.Write([]byte(msg))
+			return
 		}
 	}
 
+	// The seed of the random strategies is drawn now if not given, so that it is the same until the result
+	if req.UsesSeed() && req.Seed == nil {
+		seed := time.Now().UnixNano()
+		req.Seed = &seed
+	}
+
 	// Register the new ballot
 	var ballotId string = fmt.Sprintf("ballot%d", rsa.countBallot)
 	rsa.countBallot++
-	rsa.ballotsList[ballotId], err = restagent.NewBallot(ballotId, req.Rule, req.Deadline, req.VoterIds, req.Alts, req.TieBreak)
+	rsa.ballotsList[ballotId], err = restagent.NewBallot(ballotId, req.Rule, req.Deadline, req.VoterIds, req.Alts, req.TieBreak, req.BallotOptions, req.TieBreakOptions)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		msg := fmt.Sprintf("error /new_ballot: can't create ballot %s. "+err.Error(), ballotId)
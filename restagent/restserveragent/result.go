@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"runtime"
 	"time"
 
 	"gitlab.utc.fr/milairhu/ia04-api-rest/restagent"
@@ -38,9 +39,36 @@ func checkResultRequest(ballotsList map[string]restagent.Ballot, req restagent.R
 		return fmt.Errorf("notfinished")
 	}
 
+	// The pairwise matrix (and the majority graph) can only be computed from preferences, not from grades
+	rule, _ := restagent.LookupRule(ballotsList[req.BallotId].Rule)
+	if (req.Pairwise || req.TournamentSets) && rule.Format() == comsoc.GradeFormat {
+		return fmt.Errorf("nopairwise")
+	}
+
+	// Only the rules giving scores can return them
+	if _, ok := rule.(comsoc.Scorer); req.Scores && !ok {
+		return fmt.Errorf("noscores")
+	}
+
+	// Only the rules which can explain their result can return an explanation (not in the report mode, which gives no result)
+	if _, ok := rule.(comsoc.Explainer); req.Explain && (!ok || ballotsList[req.BallotId].Strategy == comsoc.TieBreakReport) {
+		return fmt.Errorf("noexplanation")
+	}
+
+	// The margin of victory is the number of ballots to change to change a single winner, given by complete rankings
+	ballot := ballotsList[req.BallotId]
+	if req.Margin && (rule.Format() == comsoc.GradeFormat || ballot.AllowPartial || ballot.Seats > 0 || ballot.Strategy == comsoc.TieBreakReport) {
+		return fmt.Errorf("nomargin")
+	}
+
+	// The structures of the profile are defined for complete rankings
+	if req.Structure && (rule.Format() == comsoc.GradeFormat || ballot.AllowPartial) {
+		return fmt.Errorf("nostructure")
+	}
+
 	// Check the consistency of thresholds (already checked upon receiving the vote request)
 	// Note: possibly gaining in security but losing in performance
-	if ballotsList[req.BallotId].Rule == restagent.Approval {
+	if rule.Format() == comsoc.ApprovalFormat {
 		var nbVoters int
 		for ; nbVoters < len(ballotsList[req.BallotId].HaveVoted) && ballotsList[req.BallotId].HaveVoted[nbVoters] != ""; nbVoters++ {
 		}
@@ -57,6 +85,63 @@ func checkResultRequest(ballotsList map[string]restagent.Ballot, req restagent.R
 	return
 }
 
+// Transforms the Threshold map of an approval-based ballot into a list, in the order of the votes
+func ballotThresholds(ballot restagent.Ballot) []int {
+	thresholds := make([]int, 0)
+	for _, v := range ballot.HaveVoted {
+		if v == "" {
+			break
+		}
+		thresholds = append(thresholds, ballot.Thresholds[v])
+	}
+	return thresholds
+}
+
+// Returns the alternatives of a ballot (1 to ballot.Alts)
+func ballotAlternatives(ballot restagent.Ballot) []comsoc.Alternative {
+	alts := make([]comsoc.Alternative, ballot.Alts)
+	for i := range alts {
+		alts[i] = comsoc.Alternative(i + 1)
+	}
+	return alts
+}
+
+// Returns the tie-break of a ballot, given by its tie-breaking strategy
+// Note: without votes, the strategies based on the votes use the tie-break given at ballot creation
+func ballotTieBreak(ballot restagent.Ballot, votes comsoc.Votes) ([]comsoc.Alternative, error) {
+	if votes.NbVotes() == 0 && ballot.Strategy != comsoc.TieBreakRandom {
+		return ballot.TieBreak, nil
+	}
+	switch ballot.Strategy {
+	case comsoc.TieBreakRandom:
+		return comsoc.RandomTieBreak(votes.Alts, *ballot.Seed), nil
+	case comsoc.TieBreakFirstBallot:
+		return comsoc.FirstBallotTieBreak(votes, ballot.TieBreak)
+	case comsoc.TieBreakRandomBallot:
+		return comsoc.RandomBallotTieBreak(votes, *ballot.Seed, ballot.TieBreak)
+	case comsoc.TieBreakRule:
+		second, _ := restagent.LookupRule(ballot.SecondOrderRule)
+		res, err := second.Compute(votes, secondOrderOptions(ballot.BallotOptions), ballot.TieBreak)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Ranking) != len(votes.Alts) {
+			return nil, fmt.Errorf("second-order rule %s gives no complete ranking", second.Name())
+		}
+		return res.Ranking, nil
+	}
+	return ballot.TieBreak, nil
+}
+
+// Computes the pairwise majority matrix of the votes of a ballot
+// Note: the rankings are split among the available CPUs, which is useful for ballots with many voters
+func (rsa *RestServerAgent) pairwiseMatrix(ballotId string) (*comsoc.PairwiseMatrix, error) {
+	if rsa.ballotsList[ballotId].AllowPartial {
+		return comsoc.NewWeakPairwiseMatrix(rsa.weakMap[ballotId], ballotAlternatives(rsa.ballotsList[ballotId]))
+	}
+	return comsoc.NewPairwiseMatrixParallel(comsoc.Compress(rsa.ballotsMap[ballotId]), runtime.NumCPU())
+}
+
 // Calculate the vote result by applying the desired voting method
 func (rsa *RestServerAgent) doCalcResult(w http.ResponseWriter, r *http.Request) {
 	rsa.Lock()
@@ -72,6 +157,10 @@ func (rsa *RestServerAgent) doCalcResult(w http.ResponseWriter, r *http.Request)
 		fmt.Fprint(w, err.Error())
 		return
 	}
+	// The explanation can also be requested in the URL: /result?explain=true
+	if r.URL.Query().Get("explain") == "true" {
+		req.Explain = true
+	}
 
 	// Check request
 	err = checkResultRequest(rsa.ballotsList, req)
@@ -94,7 +183,32 @@ func (rsa *RestServerAgent) doCalcResult(w http.ResponseWriter, r *http.Request)
 			return
 		case "thresholdvalue":
 			w.WriteHeader(http.StatusBadRequest) // 400
-			msg := fmt.Sprintf("error /result: ballot %s is approval and has a threshold value not in [0, %d]", req.BallotId, rsa.ballotsList[req.BallotId].Alts)
+			msg := fmt.Sprintf("error /result: ballot %s is approval-based and has a threshold value not in [0, %d]", req.BallotId, rsa.ballotsList[req.BallotId].Alts)
+			w.Write([]byte(msg))
+			return
+		case "noscores":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /result: ballot %s of type %s gives no scores", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			w.Write([]byte(msg))
+			return
+		case "noexplanation":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /result: ballot %s of type %s gives no explanation", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			w.Write([]byte(msg))
+			return
+		case "nomargin":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /result: ballot %s of type %s does not elect a single winner from complete rankings, so there is no margin of victory", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			w.Write([]byte(msg))
+			return
+		case "nostructure":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /result: ballot %s of type %s does not have complete rankings, so there is no structure of its profile", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			w.Write([]byte(msg))
+			return
+		case "nopairwise":
+			w.WriteHeader(http.StatusBadRequest) // 400
+			msg := fmt.Sprintf("error /result: ballot %s of type %s has grades, not preferences, so there is no pairwise matrix nor majority graph", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
 			w.Write([]byte(msg))
 			return
 		}
@@ -102,20 +216,62 @@ func (rsa *RestServerAgent) doCalcResult(w http.ResponseWriter, r *http.Request)
 
 	resp := restagent.ResponseResult{}
 
+	// The rule computes the result from the votes matching its format
+	ballot := rsa.ballotsList[req.BallotId]
+	rule, _ := restagent.LookupRule(ballot.Rule)
+	votes := comsoc.Votes{
+		Alts:    ballotAlternatives(ballot),
+		Profile: rsa.ballotsMap[req.BallotId],
+		Weak:    rsa.weakMap[req.BallotId],
+		Grades:  rsa.gradesMap[req.BallotId],
+	}
+	if rule.Format() == comsoc.ApprovalFormat {
+		votes.Thresholds = ballotThresholds(ballot)
+	}
+
+	tieBreak, err := ballotTieBreak(ballot, votes)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError) // 500
+		msg := fmt.Sprintf("error /result: can't compute the tie-break for ballot %s with strategy %s. "+err.Error(), req.BallotId, ballot.Strategy)
+		w.Write([]byte(msg))
+		return
+	}
+	// The tie-break is published when it is not the one given at ballot creation, with its seed if any
+	if ballot.Strategy != "" && ballot.Strategy != comsoc.TieBreakFixed && ballot.Strategy != comsoc.TieBreakReport {
+		resp.TieBreak = tieBreak
+	}
+	if ballot.UsesSeed() {
+		resp.Seed = ballot.Seed
+	}
+
 	// If no vote has been submitted, simply apply the tie-break (except for Condorcet where no Tie-Break is considered, returning 0)
-	if len(rsa.ballotsMap[req.BallotId]) == 0 {
+	if votes.NbVotes() == 0 {
 		// Note: we decide to return a result, but we could have returned an error
-		if rsa.ballotsList[req.BallotId].Rule == restagent.Condorcet {
+		if ballot.Rule == restagent.Condorcet {
 			resp.Winner = 0
+		} else if ballot.Strategy == comsoc.TieBreakReport {
+			// All the alternatives are tied
+			resp.TiedGroups = [][]comsoc.Alternative{votes.Alts}
+			if len(votes.Alts) == 1 {
+				resp.Winner = votes.Alts[0]
+			}
 		} else {
-			resp.Winner = rsa.ballotsList[req.BallotId].TieBreak[0]
-			resp.Ranking = rsa.ballotsList[req.BallotId].TieBreak
+			resp.Winner = tieBreak[0]
+			resp.Ranking = tieBreak
+			if ballot.Seats > 0 {
+				resp.Committee = tieBreak[:ballot.Seats]
+			}
+			if req.Scores {
+				// There are no scores, and all the alternatives are tied
+				resp.TiedGroups = [][]comsoc.Alternative{votes.Alts}
+				resp.BrokenTies = comsoc.BrokenTies(resp.TiedGroups, tieBreak)
+			}
 		}
 
 		serial, err := json.Marshal(resp)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, ballot.Rule)
 			w.Write([]byte(msg))
 			return
 		}
@@ -124,126 +280,117 @@ func (rsa *RestServerAgent) doCalcResult(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if rsa.ballotsList[req.BallotId].Rule == restagent.Approval {
-		// Special case of Approval, as it requires an additional parameter (the threshold)
-
-		// Transform the Threshold map into a list
-		thresholds := make([]int, 0)
-		for _, v := range rsa.ballotsList[req.BallotId].HaveVoted {
-			if v == "" {
-				break
-			}
-			thresholds = append(thresholds, rsa.ballotsList[req.BallotId].Thresholds[v])
-		}
-		tiebreak := comsoc.TieBreakFactory(rsa.ballotsList[req.BallotId].TieBreak)
-		swf, err := comsoc.MakeApprovalRankingWithTieBreak(rsa.ballotsMap[req.BallotId], thresholds, tiebreak)
-
+	if req.Pairwise || req.TournamentSets {
+		pm, err := rsa.pairwiseMatrix(req.BallotId)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't process SWF for ballot %s of type %s. "+err.Error(), req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			msg := fmt.Sprintf("error /result: can't compute the pairwise matrix for ballot %s. "+err.Error(), req.BallotId)
 			w.Write([]byte(msg))
 			return
 		}
-
-		resp.Winner = swf[0]
-		resp.Ranking = swf
-
-		serial, err := json.Marshal(resp)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
-			w.Write([]byte(msg))
-			return
+		if req.Pairwise {
+			resp.Pairwise = pm
 		}
-		w.WriteHeader(http.StatusOK) // 200
-		w.Write(serial)
-		return
+		if req.TournamentSets {
+			// Gives insight into the "close" alternatives, e.g. for Condorcet ballots without a Condorcet winner
+			sets := comsoc.NewTournamentSets(pm)
+			resp.TournamentSets = &sets
+		}
+	}
 
-	} else if rsa.ballotsList[req.BallotId].Rule == restagent.Condorcet {
-		// Special case of Condorcet, as the calculation of SWF is not possible
-		// Note: Tie-break is not used for Condorcet. Either a winner or none is returned
-		scf, err := comsoc.CondorcetWinner(rsa.ballotsMap[req.BallotId])
+	// The scores give the groups of tied alternatives, before the tie-break
+	if req.Scores || ballot.Strategy == comsoc.TieBreakReport {
+		count, err := rule.(comsoc.Scorer).Scores(votes, ballot.BallotOptions)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't process SCF for ballot %s of type %s", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			msg := fmt.Sprintf("error /result: can't process the scores for ballot %s of type %s. "+err.Error(), req.BallotId, ballot.Rule)
 			w.Write([]byte(msg))
 			return
 		}
-		if len(scf) != 0 {
-			resp.Winner = scf[0]
+		resp.TiedGroups = comsoc.TiedGroups(count)
+		if req.Scores {
+			resp.Scores = count
 		}
+	}
 
-		serial, err := json.Marshal(resp)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
-			w.Write([]byte(msg))
-			return
+	if ballot.Strategy == comsoc.TieBreakReport {
+		// Special case of the report strategy: the groups of tied alternatives are returned instead of a ranking
+		// Note: there is a winner only if no other alternative has the best score
+		if len(resp.TiedGroups[0]) == 1 {
+			resp.Winner = resp.TiedGroups[0][0]
 		}
-		w.WriteHeader(http.StatusOK) // 200
-		w.Write(serial)
-		return
-	} else if rsa.ballotsList[req.BallotId].Rule == restagent.STV {
-		// Special case of STV, as the tie-break is not applied in the same way
-		swf, err := comsoc.STV_SWF_TieBreak(rsa.ballotsMap[req.BallotId], rsa.ballotsList[req.BallotId].TieBreak)
-
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't process SWF for ballot %s of type %s. "+err.Error(), req.BallotId, rsa.ballotsList[req.BallotId].Rule)
-			w.Write([]byte(msg))
-			return
+	} else {
+		var res comsoc.RuleResult
+		if req.Explain {
+			res, resp.Explanation, err = rule.(comsoc.Explainer).Explain(votes, ballot.BallotOptions, tieBreak)
+		} else {
+			res, err = rule.Compute(votes, ballot.BallotOptions, tieBreak)
 		}
-		resp.Winner = swf[0]
-		resp.Ranking = swf
-		serial, err := json.Marshal(resp)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			msg := fmt.Sprintf("error /result: can't process the result for ballot %s of type %s. "+err.Error(), req.BallotId, ballot.Rule)
 			w.Write([]byte(msg))
 			return
 		}
-		w.WriteHeader(http.StatusOK) // 200
-		w.Write(serial)
-		return
-
-	} else {
-		var swfVote func(comsoc.Profile) (comsoc.Count, error)
-		switch rsa.ballotsList[req.BallotId].Rule {
-		case restagent.Borda:
-			swfVote = comsoc.BordaSWF
-		case restagent.Copeland:
-			// Note: Tie-break is applied for Copeland only after SWF calculation, not within the process
-			swfVote = comsoc.CopelandSWF
-		case restagent.Majority:
-			swfVote = comsoc.MajoritySWF
-		default:
-			w.WriteHeader(http.StatusBadRequest) // 400
-			msg := fmt.Sprintf("error /result: type %s is not authorized for ballot %s", rsa.ballotsList[req.BallotId].Rule, req.BallotId)
-			w.Write([]byte(msg))
-			return
+		resp.Winner = res.Winner
+		resp.Ranking = res.Ranking
+		resp.Committee = res.Committee
+		resp.Rounds = res.Rounds
+		resp.Solver = res.Solver
+		resp.DecidedBy = res.DecidedBy
+		if req.Scores {
+			resp.BrokenTies = comsoc.BrokenTies(resp.TiedGroups, res.Ranking)
 		}
+	}
 
-		// Apply tie-break to get the best element and ranking
-		var tieBreak = comsoc.TieBreakFactory(rsa.ballotsList[req.BallotId].TieBreak)
-		swfFunc := comsoc.SWFFactory(swfVote, tieBreak)
-		res, err := swfFunc(rsa.ballotsMap[req.BallotId])
+	// The margin of victory is exact for the rules which compute it, and bounded for the others
+	// Note: the tie-break of the result is kept when ballots are changed
+	if req.Margin && resp.Winner != 0 {
+		var margin comsoc.Margin
+		if mc, ok := rule.(comsoc.MarginComputer); ok {
+			margin, err = mc.Margin(votes, ballot.BallotOptions, tieBreak)
+		} else {
+			winner := comsoc.RuleWinner(rule, ballot.BallotOptions, votes.Alts, tieBreak)
+			cc, ok := rule.(comsoc.CondorcetConsistentRule)
+			condorcetConsistent := ok && cc.CondorcetConsistent(ballot.BallotOptions)
+			margin, err = comsoc.MarginBounds(votes.Profile, winner, condorcetConsistent)
+		}
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't process SWF with Tie-break for ballot %s of type %s. "+err.Error(), req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			msg := fmt.Sprintf("error /result: can't compute the margin of victory for ballot %s of type %s. "+err.Error(), req.BallotId, ballot.Rule)
 			w.Write([]byte(msg))
 			return
 		}
-		resp.Winner = res[0]
-		resp.Ranking = res
+		resp.Margin = &margin
+	}
 
-		serial, err := json.Marshal(resp)
+	// Structures of the profile, the voters being given by their ids
+	if req.Structure && len(votes.Profile) > 0 {
+		structure, err := comsoc.DetectStructure(votes.Profile)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError) // 500
-			msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, rsa.ballotsList[req.BallotId].Rule)
+			msg := fmt.Sprintf("error /result: can't detect the structure of the profile of ballot %s of type %s. "+err.Error(), req.BallotId, ballot.Rule)
 			w.Write([]byte(msg))
 			return
 		}
-		w.WriteHeader(http.StatusOK) // 200
-		w.Write(serial)
+		resp.Structure = &restagent.ProfileStructure{
+			SinglePeaked:   structure.SinglePeaked,
+			Axis:           structure.Axis,
+			SingleCrossing: structure.SingleCrossing,
+			GroupSeparable: structure.GroupSeparable,
+		}
+		for _, v := range structure.VoterOrder {
+			resp.Structure.VoterOrder = append(resp.Structure.VoterOrder, ballot.HaveVoted[v]) // the votes are stored in the order of HaveVoted
+		}
+	}
+
+	serial, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError) // 500
+		msg := fmt.Sprintf("error /result: can't serialize response for ballot %s of type %s", req.BallotId, ballot.Rule)
+		w.Write([]byte(msg))
+		return
 	}
+	w.WriteHeader(http.StatusOK) // 200
+	w.Write(serial)
 }
@@ -14,18 +14,22 @@ import (
 
 // RestServerAgent handles HTTP requests for a REST API server.
 type RestServerAgent struct {
-	sync.Mutex                              // Requests must be processed sequentially, as some requests vote while others request results
-	addr        string                      // Server address (ip:port)
-	ballotsMap  map[string]comsoc.Profile   // Associates a ballot ID with its profile
-	ballotsList map[string]restagent.Ballot // Associates a ballot ID with its Ballot object
-	countBallot int                         // Ballot counter (for generating IDs)
+	sync.Mutex                                 // Requests must be processed sequentially, as some requests vote while others request results
+	addr        string                         // Server address (ip:port)
+	ballotsMap  map[string]comsoc.Profile      // Associates a ballot ID with its profile
+	gradesMap   map[string]comsoc.GradeProfile // Associates a ballot ID with its grades (for cardinal rules)
+	weakMap     map[string]comsoc.WeakProfile  // Associates a ballot ID with its weak profile (for ballots allowing partial votes)
+	ballotsList map[string]restagent.Ballot    // Associates a ballot ID with its Ballot object
+	countBallot int                            // Ballot counter (for generating IDs)
 }
 
 // NewRestServerAgent creates a new RestServerAgent instance with the given address.
 func NewRestServerAgent(addr string) *RestServerAgent {
 	b := make(map[string]comsoc.Profile, 0)
+	g := make(map[string]comsoc.GradeProfile, 0)
+	wk := make(map[string]comsoc.WeakProfile, 0)
 	l := make(map[string]restagent.Ballot, 0)
-	return &RestServerAgent{addr: addr, ballotsMap: b, ballotsList: l, countBallot: 1}
+	return &RestServerAgent{addr: addr, ballotsMap: b, gradesMap: g, weakMap: wk, ballotsList: l, countBallot: 1}
 }
 
 // checkMethod tests the method (GET, POST, ...) of the request.
@@ -45,6 +49,8 @@ func (rsa *RestServerAgent) Start() {
 	mux.HandleFunc(endpoints.Results, rsa.doCalcResult)
 	mux.HandleFunc(endpoints.Vote, rsa.doVote)
 	mux.HandleFunc(endpoints.NewBallot, rsa.doCreateNewBallot)
+	mux.HandleFunc(endpoints.Manipulation, rsa.doManipulation)
+	mux.HandleFunc(endpoints.Import, rsa.doImport)
 
 	// Create the HTTP server
 	s := &http.Server{
@@ -44,6 +44,47 @@ func checkVoteAlts(vote []comsoc.Alternative, expected int) bool {
 	return true
 }
 
+func checkWeakVoteAlts(vote comsoc.WeakOrder, expected int) bool {
+	// Check if the weak vote only contains alternatives of the ballot, at most once each
+
+	// Note: the expected alternatives range from 1 to Ballot.Alts (inclusive)
+	seen := make([]bool, expected+1)
+	for _, class := range vote {
+		if len(class) == 0 {
+			return false
+		}
+		for _, alt := range class {
+			if alt < 1 || int(alt) > expected || seen[alt] {
+				return false
+			}
+			seen[alt] = true
+		}
+	}
+	return true
+}
+
+// Returns the weak order given by a vote on a ballot allowing partial votes:
+// either the weak preferences, or the (possibly truncated) strict preferences
+func weakVote(req restagent.RequestVote) comsoc.WeakOrder {
+	if req.WeakPrefs != nil {
+		return req.WeakPrefs
+	}
+	return comsoc.ToWeakProfile(comsoc.Profile{req.Prefs})[0]
+}
+
+// Returns the format of the votes expected by the rule of a ballot
+func ballotFormat(ballot restagent.Ballot) comsoc.BallotFormat {
+	rule, _ := restagent.LookupRule(ballot.Rule)
+	return rule.Format()
+}
+
+// Returns true if the votes of the ballot are weak orders: the ballot allows partial votes
+// and its rule declares how it treats them
+func partialVotes(ballot restagent.Ballot) bool {
+	rule, _ := restagent.LookupRule(ballot.Rule)
+	return ballot.AllowPartial && comsoc.RulePartialPolicy(rule) != comsoc.PartialUnsupported
+}
+
 func checkVote(ballotsList map[string]restagent.Ballot, deadline time.Time, req restagent.RequestVote) (err error) {
 	// Check if the ballot exists
 	_, found := ballotsList[req.BallotId]
@@ -73,13 +114,30 @@ func checkVote(ballotsList map[string]restagent.Ballot, deadline time.Time, req
 		return fmt.Errorf("alreadyfinished")
 	}
 
+	// For rules using grades, check the grades instead of the preferences
+	if ballotFormat(ballotsList[req.BallotId]) == comsoc.GradeFormat {
+		if len(req.Grades) != ballotsList[req.BallotId].Alts {
+			return fmt.Errorf("wronggrades")
+		}
+		for _, g := range req.Grades {
+			if g < 0 || g > ballotsList[req.BallotId].MaxGrade {
+				return fmt.Errorf("wronggrades")
+			}
+		}
+		return nil
+	}
+
 	// Check if the provided alternatives for the vote are correct
-	if !checkVoteAlts(req.Prefs, ballotsList[req.BallotId].Alts) {
+	if partialVotes(ballotsList[req.BallotId]) {
+		if !checkWeakVoteAlts(weakVote(req), ballotsList[req.BallotId].Alts) {
+			return fmt.Errorf("wrongalts")
+		}
+	} else if !checkVoteAlts(req.Prefs, ballotsList[req.BallotId].Alts) {
 		return fmt.Errorf("wrongalts")
 	}
 
-	// If the ballot is "approval", check if a coherent threshold is provided
-	if ballotsList[req.BallotId].Rule == "approval" {
+	// If the rule of the ballot is approval-based, check if a coherent threshold is provided
+	if ballotFormat(ballotsList[req.BallotId]) == comsoc.ApprovalFormat {
 		if req.Options == nil || len(req.Options) != 1 || req.Options[0] < 0 || req.Options[0] > ballotsList[req.BallotId].Alts {
 			return fmt.Errorf("wrongthreshold")
 		}
@@ -132,6 +190,11 @@ func (rsa *RestServerAgent) doVote(w http.ResponseWriter, r *http.Request) {
 			msg := fmt.Sprintf("error /vote: alternatives provided for ballot %s are not correct", req.BallotId)
 			w.Write([]byte(msg))
 			return
+		case "wronggrades":
+			w.WriteHeader(http.StatusBadRequest) //400
+			msg := fmt.Sprintf("error /vote: grades %v provided for ballot %s are not correct: one grade in [0, %d] is expected per alternative", req.Grades, req.BallotId, rsa.ballotsList[req.BallotId].MaxGrade)
+			w.Write([]byte(msg))
+			return
 		case "wrongthreshold":
 			w.WriteHeader(http.StatusBadRequest) //400
 			msg := fmt.Sprintf("error /vote: threshold %d provided for ballot %s is not correct", req.Options, req.BallotId)
@@ -141,7 +204,7 @@ func (rsa *RestServerAgent) doVote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save the threshold if necessary
-	if rsa.ballotsList[req.BallotId].Rule == restagent.Approval {
+	if ballotFormat(rsa.ballotsList[req.BallotId]) == comsoc.ApprovalFormat {
 		_, found := rsa.ballotsList[req.BallotId].Thresholds[req.AgentId]
 		if found {
 			w.WriteHeader(http.StatusBadRequest) //400
@@ -153,7 +216,17 @@ func (rsa *RestServerAgent) doVote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save the vote for the ballot
-	rsa.ballotsMap[req.BallotId] = append(rsa.ballotsMap[req.BallotId], req.Prefs)
+	if ballotFormat(rsa.ballotsList[req.BallotId]) == comsoc.GradeFormat {
+		grades := make(map[comsoc.Alternative]int, len(req.Grades))
+		for i, g := range req.Grades {
+			grades[comsoc.Alternative(i+1)] = g
+		}
+		rsa.gradesMap[req.BallotId] = append(rsa.gradesMap[req.BallotId], grades)
+	} else if partialVotes(rsa.ballotsList[req.BallotId]) {
+		rsa.weakMap[req.BallotId] = append(rsa.weakMap[req.BallotId], weakVote(req))
+	} else {
+		rsa.ballotsMap[req.BallotId] = append(rsa.ballotsMap[req.BallotId], req.Prefs)
+	}
 
 	// Record that the agent has voted
 	for i := 0; i < len(rsa.ballotsList[req.BallotId].HaveVoted); i++ {
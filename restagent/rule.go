@@ -8,5 +8,42 @@ const Condorcet = "condorcet"
 const Copeland = "copeland"
 const Majority = "majority"
 const STV = "stv"
+const Schulze = "schulze"
+const RankedPairs = "ranked_pairs"
+const Kemeny = "kemeny"
+const Minimax = "minimax"
+const Baldwin = "baldwin"
+const Nanson = "nanson"
+const Coombs = "coombs"
+const Bucklin = "bucklin"
+const Scoring = "scoring"
+const MultiSTV = "multi_stv"
+const PAV = "pav"
+const SeqPAV = "seq_pav"
+const Phragmen = "phragmen"
+const ChamberlinCourant = "cc"
+const Range = "range"
+const STAR = "star"
+const MajorityJudgment = "majority_judgment"
+const Black = "black"
+const CondorcetIRV = "condorcet_irv"
+const CondorcetCompletion = "condorcet_completion"
 
-var Rules = []string{Approval, Borda, Condorcet, Copeland, Majority, STV}
+// Names of the registered rules, in the order of their registration (see RegisterRule)
+var Rules []string
+
+// Rules electing a committee of Ballot.Seats alternatives
+var CommitteeRules = []string{MultiSTV, PAV, SeqPAV, Phragmen, ChamberlinCourant}
+
+// Rules for which voters give grades to the alternatives rather than preferences
+var CardinalRules = []string{Range, STAR, MajorityJudgment}
+
+// Returns true if rule belongs to rules
+func ContainsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
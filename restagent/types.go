@@ -7,19 +7,28 @@ import (
 )
 
 // Types used for the /new_ballot request
+
+// Options specific to some voting methods (all optional), checked by the rule of the ballot
+type BallotOptions = comsoc.RuleOptions
+
+// Strategy used to break ties (all optional)
+type TieBreakOptions = comsoc.TieBreakOptions
+
 type Ballot struct {
-	BallotId   string               // Ballot identifier
-	Rule       string               // Voting method
-	Deadline   time.Time            // Voting deadline
-	VoterIds   []string             // List of agents eligible to vote
-	Alts       int                  // Number of alternatives (from 1 to Alts)
-	TieBreak   []comsoc.Alternative // Preference order of alternatives in case of a tie
-	HaveVoted  []string             // Names of agents who have voted
-	Thresholds map[string]int       // Contains the thresholds of each voter (for approval voting)
+	BallotId        string               // Ballot identifier
+	Rule            string               // Voting method
+	Deadline        time.Time            // Voting deadline
+	VoterIds        []string             // List of agents eligible to vote
+	Alts            int                  // Number of alternatives (from 1 to Alts)
+	TieBreak        []comsoc.Alternative // Preference order of alternatives in case of a tie
+	HaveVoted       []string             // Names of agents who have voted
+	Thresholds      map[string]int       // Contains the thresholds of each voter (for approval voting)
+	BallotOptions                        // Options specific to the voting method
+	TieBreakOptions                      // Strategy used to break ties
 }
 
 // Constructor for a Ballot
-func NewBallot(ballotId string, rule string, deadline string, voterIds []string, alts int, tieBreak []comsoc.Alternative) (Ballot, error) {
+func NewBallot(ballotId string, rule string, deadline string, voterIds []string, alts int, tieBreak []comsoc.Alternative, options BallotOptions, tieBreakOptions TieBreakOptions) (Ballot, error) {
 	// Check the date format
 	date, err := time.Parse(time.RFC3339, deadline)
 	if err != nil {
@@ -28,23 +37,27 @@ func NewBallot(ballotId string, rule string, deadline string, voterIds []string,
 	haveVoted := make([]string, len(voterIds))
 	thresholds := make(map[string]int)
 	return Ballot{
-		BallotId:   ballotId,
-		Rule:       rule,
-		Deadline:   date,
-		VoterIds:   voterIds,
-		Alts:       alts,
-		TieBreak:   tieBreak,
-		HaveVoted:  haveVoted,
-		Thresholds: thresholds,
+		BallotId:        ballotId,
+		Rule:            rule,
+		Deadline:        date,
+		VoterIds:        voterIds,
+		Alts:            alts,
+		TieBreak:        tieBreak,
+		HaveVoted:       haveVoted,
+		Thresholds:      thresholds,
+		BallotOptions:   options,
+		TieBreakOptions: tieBreakOptions,
 	}, nil
 }
 
 type RequestNewBallot struct {
-	Rule     string               `json:"rule"`      // Voting method
-	Deadline string               `json:"deadline"`  // Voting deadline
-	VoterIds []string             `json:"voter-ids"` // List of agents eligible to vote
-	Alts     int                  `json:"#alts"`     // Number of alternatives (from 1 to Alts)
-	TieBreak []comsoc.Alternative `json:"tie-break"` // Preference order of alternatives in case of a tie
+	Rule            string               `json:"rule"`      // Voting method
+	Deadline        string               `json:"deadline"`  // Voting deadline
+	VoterIds        []string             `json:"voter-ids"` // List of agents eligible to vote
+	Alts            int                  `json:"#alts"`     // Number of alternatives (from 1 to Alts)
+	TieBreak        []comsoc.Alternative `json:"tie-break"` // Preference order of alternatives in case of a tie
+	BallotOptions                        // Options specific to the voting method (flattened in the JSON object)
+	TieBreakOptions                      // Strategy used to break ties (flattened in the JSON object)
 }
 
 type ResponseNewBallot struct {
@@ -54,20 +67,76 @@ type ResponseNewBallot struct {
 
 // Type used for the /vote request
 type RequestVote struct {
-	AgentId  string               `json:"agent-id"`  // Id of the voting agent
-	BallotId string               `json:"ballot-id"` // Id of the ballot being voted on
-	Prefs    []comsoc.Alternative `json:"prefs"`     // Ordered preferences of the voting agent
-	Options  []int                `json:"options"`   // Used for the threshold in approval voting
+	AgentId   string               `json:"agent-id"`             // Id of the voting agent
+	BallotId  string               `json:"ballot-id"`            // Id of the ballot being voted on
+	Prefs     []comsoc.Alternative `json:"prefs"`                // Ordered preferences of the voting agent
+	Options   []int                `json:"options"`              // Used for the threshold in approval voting
+	Grades    []int                `json:"grades,omitempty"`     // Grade of each alternative (Grades[i] for alternative i+1), for cardinal rules
+	WeakPrefs comsoc.WeakOrder     `json:"weak-prefs,omitempty"` // Preferences with ties (indifference classes, best first), possibly truncated, if the ballot allows partial votes
 }
 
 // Types used for the /result request
 
 type RequestResult struct {
-	BallotId string `json:"ballot-id"` // Id of the ballot for which the result is requested
+	BallotId       string `json:"ballot-id"`                 // Id of the ballot for which the result is requested
+	Pairwise       bool   `json:"pairwise,omitempty"`        // If true, the pairwise majority matrix of the votes is returned
+	TournamentSets bool   `json:"tournament-sets,omitempty"` // If true, the Smith, Schwartz, uncovered and Banks sets are returned
+	Scores         bool   `json:"scores,omitempty"`          // If true, the scores, the tied groups and the ties broken by the tie-break are returned (for rules giving scores)
+	Explain        bool   `json:"explain,omitempty"`         // If true, the explanation of the result is returned (also set by /result?explain=true)
+	Margin         bool   `json:"margin,omitempty"`          // If true, the margin of victory is returned (for ballots electing a single winner from complete rankings)
+	Structure      bool   `json:"structure,omitempty"`       // If true, the structures of the profile (single-peaked, single-crossing, group-separable) are returned (for complete rankings)
 }
 
 type ResponseResult struct {
 	// Object returned if code 200
-	Winner  comsoc.Alternative   `json:"winner"`            // Winning alternative
-	Ranking []comsoc.Alternative `json:"ranking,omitempty"` // Ranking of alternatives (Optional field)
+	Winner         comsoc.Alternative     `json:"winner"`                    // Winning alternative
+	Ranking        []comsoc.Alternative   `json:"ranking,omitempty"`         // Ranking of alternatives (Optional field)
+	Solver         string                 `json:"solver,omitempty"`          // "exact" or "heuristic", for Kemeny, PAV and CC ballots (Optional field)
+	Committee      []comsoc.Alternative   `json:"committee,omitempty"`       // Elected committee, for multi-winner ballots (Optional field)
+	Rounds         []comsoc.STVRound      `json:"rounds,omitempty"`          // Tallies of each round, for multi_stv ballots (Optional field)
+	Pairwise       *comsoc.PairwiseMatrix `json:"pairwise,omitempty"`        // Pairwise majority matrix, if requested (Optional field)
+	TournamentSets *comsoc.TournamentSets `json:"tournament-sets,omitempty"` // Tournament solutions of the majority graph, if requested, without Banks beyond comsoc.BanksLimit alternatives (Optional field)
+	DecidedBy      string                 `json:"decided-by,omitempty"`      // "condorcet" or the fallback rule, for Condorcet completion ballots (Optional field)
+	TieBreak       []comsoc.Alternative   `json:"tie-break,omitempty"`       // Tie-break used, if it is not the one given at ballot creation (Optional field)
+	Seed           *int64                 `json:"seed,omitempty"`            // Seed of the tie-break, for the random strategies (Optional field)
+	TiedGroups     [][]comsoc.Alternative `json:"tied-groups,omitempty"`     // Groups of tied alternatives by decreasing score (weak order before tie-break), for the report strategy or if requested (Optional field)
+	Scores         comsoc.FloatCount      `json:"scores,omitempty"`          // Score of each alternative, if requested (Optional field)
+	BrokenTies     [][]comsoc.Alternative `json:"broken-ties,omitempty"`     // Tied groups with the order given by the tie-break, if requested (Optional field)
+	Explanation    comsoc.Explanation     `json:"explanation,omitempty"`     // Rounds of the rule, if requested (Optional field)
+	Margin         *comsoc.Margin         `json:"margin,omitempty"`          // Bounds of the number of ballots to change to change the winner, if requested (Optional field)
+	Structure      *ProfileStructure      `json:"structure,omitempty"`       // Structures detected in the profile, if requested and there are votes (Optional field)
+}
+
+// Structures of the profile of a ballot (see comsoc.DetectStructure)
+type ProfileStructure struct {
+	SinglePeaked   bool                 `json:"single-peaked"`
+	Axis           []comsoc.Alternative `json:"axis,omitempty"` // Axis on which the profile is single-peaked
+	SingleCrossing bool                 `json:"single-crossing"`
+	VoterOrder     []string             `json:"voter-order,omitempty"` // Ids of the voters in an order for which the profile is single-crossing
+	GroupSeparable bool                 `json:"group-separable"`
+}
+
+// Types used for the /manipulation request
+
+type RequestManipulation struct {
+	BallotId      string `json:"ballot-id"`                // Id of the (closed) ballot to analyse
+	CoalitionSize int    `json:"coalition-size,omitempty"` // Largest coalition of voters tried (1 by default: a single voter, at most comsoc.MaxCoalitionSize and the number of votes)
+}
+
+type ResponseManipulation struct {
+	// Object returned if code 200
+	Manipulable   bool                   `json:"manipulable"`       // True if a coalition of at most coalition-size voters can obtain a winner they all prefer
+	Exhaustive    bool                   `json:"exhaustive"`        // False if only compromising and burying were tried for some coalitions, or the search budget ran out, so that a manipulation may have been missed
+	SincereWinner comsoc.Alternative     `json:"sincere-winner"`    // Winner of the ballot
+	Winner        comsoc.Alternative     `json:"winner,omitempty"`  // Winner obtained by the manipulation (Optional field)
+	Voters        []string               `json:"voters,omitempty"`  // Ids of the manipulating voters (Optional field)
+	Ballots       [][]comsoc.Alternative `json:"ballots,omitempty"` // Ballots reported by the manipulating voters, in the order of voters (Optional field)
+}
+
+// Types used for the /import request
+
+type RequestImport struct {
+	BallotId string   `json:"ballot-id"` // Id of the (open) ballot receiving the votes
+	VoterIds []string `json:"voter-ids"` // Ids of the voters, in the order of the votes of the file (the counts being expanded)
+	Data     string   `json:"data"`      // Content of the PrefLib file (soc, soi, toc, toi or cat)
 }